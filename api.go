@@ -0,0 +1,400 @@
+package srcutil
+
+import (
+	"bytes"
+	"fmt"
+	"go/types"
+	"sort"
+)
+
+// API is a stable and serializable description of the exported surface of a
+// package. Types are written relative to the package, so an API created from
+// two different checkouts of the same package may be compared with DiffAPI.
+type API struct {
+	ImportPath string     `json:"importPath"`
+	Name       string     `json:"name"`
+	Consts     []APIValue `json:"consts,omitempty"`
+	Vars       []APIValue `json:"vars,omitempty"`
+	Funcs      []APIFunc  `json:"funcs,omitempty"`
+	Types      []APIType  `json:"types,omitempty"`
+}
+
+// APIValue describes an exported const, var or struct field.
+type APIValue struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+
+	// Value is the exact value of a const, it is empty for vars and fields.
+	Value string `json:"value,omitempty"`
+
+	// Embedded is true for embedded struct fields.
+	Embedded bool `json:"embedded,omitempty"`
+}
+
+// APIFunc describes an exported func or method. The Signature omits parameter
+// names since changing them does not affect callers.
+type APIFunc struct {
+	Name      string `json:"name"`
+	Signature string `json:"signature"`
+
+	// Recv is "T" or "*T" for methods of named types depending on which method
+	// set the method belongs to, it is empty for funcs and interface methods.
+	Recv string `json:"recv,omitempty"`
+}
+
+// APIType describes an exported named type.
+type APIType struct {
+	Name string `json:"name"`
+
+	// Kind is one of "struct", "interface", "alias" or "defined".
+	Kind       string `json:"kind"`
+	TypeParams string `json:"typeParams,omitempty"`
+
+	// Underlying is the type definition for the "alias" and "defined" kinds.
+	Underlying string     `json:"underlying,omitempty"`
+	Fields     []APIValue `json:"fields,omitempty"`
+	Methods    []APIFunc  `json:"methods,omitempty"`
+}
+
+// API returns the API of this package. Identifiers declared within _test.go
+// files are not included. An error is returned when the package could not be
+// type checked.
+func (p *Package) API() (API, error) {
+	if err := p.init(); err != nil {
+		return API{}, err
+	}
+	qual := types.RelativeTo(p.tc.typesPkg)
+	api := API{ImportPath: p.ImportPath, Name: p.Name}
+	scope := p.tc.typesPkg.Scope()
+	for _, name := range scope.Names() {
+		obj := scope.Lookup(name)
+		if !obj.Exported() || isTest(name, "Test") || isTest(name, "Example") {
+			continue
+		}
+		if isTestFile(p.tc.fileSet.Position(obj.Pos())) {
+			continue
+		}
+		switch obj := obj.(type) {
+		case *types.Const:
+			api.Consts = append(api.Consts, APIValue{
+				Name: name, Type: types.TypeString(obj.Type(), qual),
+				Value: obj.Val().ExactString()})
+		case *types.Var:
+			api.Vars = append(api.Vars, APIValue{
+				Name: name, Type: types.TypeString(obj.Type(), qual)})
+		case *types.Func:
+			api.Funcs = append(api.Funcs, APIFunc{
+				Name: name, Signature: apiSignature(obj.Type().(*types.Signature), qual)})
+		case *types.TypeName:
+			api.Types = append(api.Types, apiType(obj, qual))
+		}
+	}
+	return api, nil
+}
+
+// typeDecl is the declaration of an exported type name shared by API and
+// Export, which each describe the methods of defined types differently.
+type typeDecl struct {
+	kind       string // one of "struct", "interface", "alias" or "defined"
+	underlying string // the definition for the "alias" and "defined" kinds
+
+	named   *types.Named  // nil for aliases
+	fields  []*types.Var  // exported struct fields
+	tags    []string      // tags of the exported struct fields
+	methods []*types.Func // exported interface methods
+}
+
+func newTypeDecl(obj *types.TypeName, qual types.Qualifier) typeDecl {
+	var d typeDecl
+	if obj.IsAlias() {
+		d.kind = "alias"
+		d.underlying = types.TypeString(types.Unalias(obj.Type()), qual)
+		return d
+	}
+	named, ok := obj.Type().(*types.Named)
+	if !ok {
+		d.kind = "defined"
+		d.underlying = types.TypeString(obj.Type().Underlying(), qual)
+		return d
+	}
+	d.named = named
+
+	switch under := named.Underlying().(type) {
+	case *types.Struct:
+		d.kind = "struct"
+		for i := 0; i < under.NumFields(); i++ {
+			if f := under.Field(i); f.Exported() {
+				d.fields = append(d.fields, f)
+				d.tags = append(d.tags, under.Tag(i))
+			}
+		}
+	case *types.Interface:
+		d.kind = "interface"
+		for i := 0; i < under.NumMethods(); i++ {
+			if m := under.Method(i); m.Exported() {
+				d.methods = append(d.methods, m)
+			}
+		}
+	default:
+		d.kind = "defined"
+		d.underlying = types.TypeString(under, qual)
+	}
+	return d
+}
+
+func apiType(obj *types.TypeName, qual types.Qualifier) APIType {
+	d := newTypeDecl(obj, qual)
+	typ := APIType{Name: obj.Name(), Kind: d.kind, Underlying: d.underlying}
+	if d.named == nil {
+		return typ
+	}
+	typ.TypeParams = apiTypeParams(d.named.TypeParams(), qual)
+	for _, f := range d.fields {
+		typ.Fields = append(typ.Fields, APIValue{
+			Name: f.Name(), Type: types.TypeString(f.Type(), qual),
+			Embedded: f.Embedded()})
+	}
+	for _, m := range d.methods {
+		typ.Methods = append(typ.Methods, APIFunc{
+			Name: m.Name(), Signature: apiSignature(m.Type().(*types.Signature), qual)})
+	}
+	if d.kind == "interface" {
+		return typ
+	}
+
+	valueSet := types.NewMethodSet(d.named)
+	ptrSet := types.NewMethodSet(types.NewPointer(d.named))
+	for i := 0; i < ptrSet.Len(); i++ {
+		m := ptrSet.At(i).Obj()
+		if !m.Exported() {
+			continue
+		}
+		recv := "*" + obj.Name()
+		if valueSet.Lookup(m.Pkg(), m.Name()) != nil {
+			recv = obj.Name()
+		}
+		typ.Methods = append(typ.Methods, APIFunc{
+			Name: m.Name(), Recv: recv,
+			Signature: apiSignature(m.Type().(*types.Signature), qual)})
+	}
+	return typ
+}
+
+func apiTypeParams(tparams *types.TypeParamList, qual types.Qualifier) string {
+	if tparams.Len() == 0 {
+		return ``
+	}
+	var buf bytes.Buffer
+	buf.WriteByte('[')
+	for i := 0; i < tparams.Len(); i++ {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		tparam := tparams.At(i)
+		buf.WriteString(tparam.Obj().Name() + " ")
+		buf.WriteString(types.TypeString(tparam.Constraint(), qual))
+	}
+	buf.WriteByte(']')
+	return buf.String()
+}
+
+func apiSignature(sig *types.Signature, qual types.Qualifier) string {
+	var buf bytes.Buffer
+	buf.WriteString("func" + apiTypeParams(sig.TypeParams(), qual) + "(")
+	params := sig.Params()
+	for i := 0; i < params.Len(); i++ {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		typ := params.At(i).Type()
+		if sig.Variadic() && i == params.Len()-1 {
+			buf.WriteString("...")
+			typ = typ.(*types.Slice).Elem()
+		}
+		buf.WriteString(types.TypeString(typ, qual))
+	}
+	buf.WriteByte(')')
+
+	results := sig.Results()
+	if results.Len() > 1 {
+		buf.WriteString(" (")
+	} else if results.Len() == 1 {
+		buf.WriteByte(' ')
+	}
+	for i := 0; i < results.Len(); i++ {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(types.TypeString(results.At(i).Type(), qual))
+	}
+	if results.Len() > 1 {
+		buf.WriteByte(')')
+	}
+	return buf.String()
+}
+
+// APIChange is a single difference between two versions of an API.
+type APIChange struct {
+	// Name is the identifier that changed, methods and fields are qualified by
+	// their type name, I.E. "Reader.Read".
+	Name    string `json:"name"`
+	Message string `json:"message"`
+
+	// Breaking is true when the change may cause code that uses the old API to
+	// no longer compile.
+	Breaking bool `json:"breaking"`
+}
+
+// String implements fmt.Stringer.
+func (c APIChange) String() string {
+	kind := "compatible"
+	if c.Breaking {
+		kind = "breaking"
+	}
+	return fmt.Sprintf("%s: %s: %s", kind, c.Name, c.Message)
+}
+
+// APIChanges is a slice of APIChange.
+type APIChanges []APIChange
+
+// Breaking returns true if any of the changes are breaking.
+func (s APIChanges) Breaking() bool {
+	for _, c := range s {
+		if c.Breaking {
+			return true
+		}
+	}
+	return false
+}
+
+// DiffAPI returns the changes from the old API to the new API ordered by name.
+// Additions are compatible, while removals and changes to the type of an
+// existing identifier are breaking. Moving a method from a value to a pointer
+// receiver and adding a method to an interface are also breaking.
+func DiffAPI(old, new API) APIChanges {
+	var d apiDiff
+	d.values("const", old.Consts, new.Consts)
+	d.values("var", old.Vars, new.Vars)
+	d.funcs(``, false, old.Funcs, new.Funcs)
+
+	oldTypes := make(map[string]APIType)
+	for _, typ := range old.Types {
+		oldTypes[typ.Name] = typ
+	}
+	for _, typ := range new.Types {
+		o, ok := oldTypes[typ.Name]
+		delete(oldTypes, typ.Name)
+		if !ok {
+			d.add(typ.Name, false, "type added")
+			continue
+		}
+		d.types(o, typ)
+	}
+	for name := range oldTypes {
+		d.add(name, true, "type removed")
+	}
+
+	sort.Slice(d.changes, func(i, j int) bool {
+		a, b := d.changes[i], d.changes[j]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return a.Message < b.Message
+	})
+	return d.changes
+}
+
+type apiDiff struct {
+	changes APIChanges
+}
+
+func (d *apiDiff) add(name string, breaking bool, format string, args ...interface{}) {
+	d.changes = append(d.changes, APIChange{
+		Name: name, Breaking: breaking, Message: fmt.Sprintf(format, args...)})
+}
+
+func (d *apiDiff) values(kind string, old, new []APIValue) {
+	oldValues := make(map[string]APIValue)
+	for _, v := range old {
+		oldValues[v.Name] = v
+	}
+	for _, v := range new {
+		o, ok := oldValues[v.Name]
+		delete(oldValues, v.Name)
+		switch {
+		case !ok:
+			d.add(v.Name, false, "%s added", kind)
+		case o.Type != v.Type:
+			d.add(v.Name, true, "%s type changed from %s to %s", kind, o.Type, v.Type)
+		case o.Value != v.Value:
+			d.add(v.Name, true, "%s value changed from %s to %s", kind, o.Value, v.Value)
+		}
+	}
+	for name := range oldValues {
+		d.add(name, true, "%s removed", kind)
+	}
+}
+
+func (d *apiDiff) funcs(typeName string, iface bool, old, new []APIFunc) {
+	kind, prefix := "func", ``
+	if len(typeName) > 0 {
+		kind, prefix = "method", typeName+"."
+	}
+	oldFuncs := make(map[string]APIFunc)
+	for _, f := range old {
+		oldFuncs[f.Name] = f
+	}
+	for _, f := range new {
+		o, ok := oldFuncs[f.Name]
+		delete(oldFuncs, f.Name)
+		switch {
+		case !ok && iface:
+			d.add(prefix+f.Name, true, "method added to interface")
+		case !ok:
+			d.add(prefix+f.Name, false, "%s added", kind)
+		case o.Signature != f.Signature:
+			d.add(prefix+f.Name, true, "%s signature changed from %s to %s",
+				kind, o.Signature, f.Signature)
+		case o.Recv != f.Recv:
+			d.add(prefix+f.Name, o.Recv == typeName,
+				"receiver changed from %s to %s", o.Recv, f.Recv)
+		}
+	}
+	for name := range oldFuncs {
+		d.add(prefix+name, true, "%s removed", kind)
+	}
+}
+
+func (d *apiDiff) types(old, new APIType) {
+	switch {
+	case old.Kind != new.Kind:
+		d.add(new.Name, true, "type changed from %s to %s", old.Kind, new.Kind)
+		return
+	case old.TypeParams != new.TypeParams:
+		d.add(new.Name, true, "type parameters changed from %s to %s",
+			old.TypeParams, new.TypeParams)
+	case old.Underlying != new.Underlying:
+		d.add(new.Name, true, "type changed from %s to %s",
+			old.Underlying, new.Underlying)
+	}
+
+	oldFields := make(map[string]APIValue)
+	for _, f := range old.Fields {
+		oldFields[f.Name] = f
+	}
+	for _, f := range new.Fields {
+		o, ok := oldFields[f.Name]
+		delete(oldFields, f.Name)
+		switch {
+		case !ok:
+			d.add(new.Name+"."+f.Name, false, "field added")
+		case o.Type != f.Type:
+			d.add(new.Name+"."+f.Name, true, "field type changed from %s to %s",
+				o.Type, f.Type)
+		}
+	}
+	for name := range oldFields {
+		d.add(new.Name+"."+name, true, "field removed")
+	}
+	d.funcs(new.Name, new.Kind == "interface", old.Methods, new.Methods)
+}
@@ -0,0 +1,100 @@
+package srcutil
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestAPI(t *testing.T) {
+	const api = "github.com/cstockton/go-srcutil/testdata/api/"
+	ctx := FromWorkDir()
+	pkgs, err := ctx.ImportAll(api+"v1", api+"v2", tPkg.ImportPath)
+	tmust(t, err)
+	oldAPI, err := pkgs[0].API()
+	tmust(t, err)
+	newAPI, err := pkgs[1].API()
+	tmust(t, err)
+
+	t.Run("API", func(t *testing.T) {
+		got, err := pkgs[2].API()
+		tmust(t, err)
+		teq(t, tPkg.ImportPath, got.ImportPath)
+		teq(t, APIValue{Name: "ConstantOne", Type: "untyped int", Value: "42"}, got.Consts[0])
+		teq(t, APIFunc{Name: "StringFunc", Signature: "func(string) string"}, got.Funcs[2])
+		teq(t, 2, len(got.Types))
+		for _, f := range got.Funcs {
+			if isTest(f.Name, "Test") {
+				t.Errorf("exp test funcs to be excluded; got %v", f.Name)
+			}
+		}
+
+		ps := got.Types[0]
+		teq(t, "PublicStruct", ps.Name)
+		teq(t, "struct", ps.Kind)
+		teq(t, []APIValue{{Name: "Name", Type: "string"}, {Name: "Number", Type: "int"}},
+			ps.Fields)
+		teq(t, APIFunc{Name: "MethodOne", Recv: "PublicStruct", Signature: "func()"},
+			ps.Methods[0])
+		teq(t, APIFunc{Name: "MethodOneP", Recv: "*PublicStruct", Signature: "func()"},
+			ps.Methods[1])
+	})
+	t.Run("Types", func(t *testing.T) {
+		types := make(map[string]APIType)
+		for _, typ := range oldAPI.Types {
+			types[typ.Name] = typ
+		}
+		teq(t, "alias", types["Alias"].Kind)
+		teq(t, "Struct", types["Alias"].Underlying)
+		teq(t, "[T comparable]", types["Generic"].TypeParams)
+		teq(t, "defined", types["Number"].Kind)
+		teq(t, "int", types["Number"].Underlying)
+		teq(t, []APIFunc{
+			{Name: "Close", Signature: "func() error"},
+			{Name: "Read", Signature: "func([]byte) (int, error)"}}, types["Iface"].Methods)
+		for _, f := range oldAPI.Funcs {
+			if f.Name == "Same" {
+				teq(t, "func(io.Reader, ...int) (int, error)", f.Signature)
+			}
+		}
+	})
+	t.Run("JSON", func(t *testing.T) {
+		data, err := json.Marshal(oldAPI)
+		tmust(t, err)
+		var got API
+		tmust(t, json.Unmarshal(data, &got))
+		teq(t, oldAPI, got)
+		teq(t, 0, len(DiffAPI(oldAPI, got)))
+	})
+	t.Run("DiffAPI", func(t *testing.T) {
+		got := DiffAPI(oldAPI, newAPI)
+		teq(t, true, got.Breaking())
+		exp := []string{
+			"compatible: Added: const added",
+			"compatible: AddedFunc: func added",
+			"breaking: Const: const value changed from 1 to 2",
+			"breaking: Func: func signature changed from func(int, string) error to func(int, string, bool) error",
+			"breaking: Iface.Flush: method added to interface",
+			"breaking: Number: type changed from int to float64",
+			"breaking: Removed: const removed",
+			"compatible: Struct.Added: field added",
+			"compatible: Struct.Added2: method added",
+			"breaking: Struct.Changed: field type changed from int to string",
+			"breaking: Struct.Gone: method removed",
+			"breaking: Struct.Moved: receiver changed from Struct to *Struct",
+			"breaking: Struct.Removed: field removed",
+			"breaking: Var: var type changed from int to string",
+		}
+		var strs []string
+		for _, c := range got {
+			strs = append(strs, c.String())
+		}
+		teq(t, exp, strs)
+
+		got = DiffAPI(oldAPI, oldAPI)
+		teq(t, 0, len(got))
+		teq(t, false, got.Breaking())
+
+		got = DiffAPI(API{}, oldAPI)
+		teq(t, false, got.Breaking())
+	})
+}
@@ -0,0 +1,279 @@
+// Command srcutil exposes the srcutil package from the command line.
+//
+// Usage:
+//
+//	srcutil <command> [-format text|json|tsv] [flags] [packages]
+//	srcutil methods [-format text|json|tsv] <package> [types]
+//
+// The commands are:
+//
+//	funcs      list the exported funcs of each package
+//	methods    list the methods of each named type, or only the given types
+//	structs    list the fields of each exported struct
+//	notes      list marked comments such as "TODO(uid): body", or also
+//	           comments such as "TODO: body" with -bare
+//	files      list the source files, or the test files with -tests
+//	tags       write a ctags file, an Emacs TAGS file with -etags or a JSON
+//	           symbol index with -format json
+//	lsif       write an LSIF code intelligence index
+//
+// Packages may be import paths or patterns such as "./..." as described by
+// "go help packages".
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/cstockton/go-srcutil"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "srcutil:", err)
+		os.Exit(2)
+	}
+}
+
+var errUsage = errors.New(
+	"usage: srcutil <funcs|methods|structs|notes|files|tags|lsif> [flags] [packages]")
+
+// table is the output of a command, each row has a value for each column.
+type table struct {
+	columns []string
+	rows    [][]string
+}
+
+func (t *table) add(row ...string) {
+	t.rows = append(t.rows, row)
+}
+
+func (t *table) write(w io.Writer, format string) error {
+	switch format {
+	case "text":
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		for _, row := range t.rows {
+			fmt.Fprintln(tw, strings.Join(row, "\t"))
+		}
+		return tw.Flush()
+	case "tsv":
+		for _, row := range t.rows {
+			if _, err := fmt.Fprintln(w, strings.Join(row, "\t")); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "json":
+		out := make([]map[string]string, len(t.rows))
+		for i, row := range t.rows {
+			out[i] = make(map[string]string)
+			for j, col := range t.columns {
+				out[i][col] = row[j]
+			}
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent(``, `  `)
+		return enc.Encode(out)
+	}
+	return fmt.Errorf("unknown format %q, must be text, json or tsv", format)
+}
+
+func run(args []string, w io.Writer) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+	name, args := args[0], args[1:]
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	format := flags.String("format", "text", "output format: text, json or tsv")
+
+	var (
+		cmd       func(pkgs []*srcutil.Package) (*table, error)
+		write     func(pkgs []*srcutil.Package) error
+		typeNames []string
+	)
+	switch name {
+	case "funcs":
+		cmd = funcs
+	case "methods":
+		cmd = func(pkgs []*srcutil.Package) (*table, error) {
+			return methods(pkgs, typeNames)
+		}
+	case "structs":
+		cmd = structs
+	case "notes":
+		marker := flags.String("marker", ``, "only list notes with this marker")
+		uid := flags.String("uid", ``, "only list notes with this uid")
+		bare := flags.Bool("bare", false, "also list notes without a uid")
+		cmd = func(pkgs []*srcutil.Package) (*table, error) {
+			return notes(pkgs, *marker, *uid, *bare)
+		}
+	case "files":
+		tests := flags.Bool("tests", false, "list test files instead of sources")
+		cmd = func(pkgs []*srcutil.Package) (*table, error) {
+			return files(pkgs, *tests), nil
+		}
+	case "tags":
+		etags := flags.Bool("etags", false, "write an Emacs TAGS file")
+		write = func(pkgs []*srcutil.Package) error {
+			return tags(w, pkgs, *format, *etags)
+		}
+	case "lsif":
+		write = func(pkgs []*srcutil.Package) error {
+			return srcutil.WriteLSIF(w, ".", pkgs)
+		}
+	default:
+		return errUsage
+	}
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("%s: %v", name, err)
+	}
+
+	patterns := flags.Args()
+	if name == "methods" && len(patterns) > 1 {
+		// methods accepts a single package followed by type names.
+		patterns, typeNames = patterns[:1], patterns[1:]
+	}
+	if len(patterns) == 0 {
+		patterns = []string{"."}
+	}
+	ctx := srcutil.FromWorkDir()
+	pkgNames, err := ctx.Match(patterns...)
+	if err != nil {
+		return err
+	}
+	pkgs, err := ctx.ImportAll(pkgNames...)
+	if err != nil {
+		return err
+	}
+	if write != nil {
+		return write(pkgs)
+	}
+	t, err := cmd(pkgs)
+	if err != nil {
+		return err
+	}
+	return t.write(w, *format)
+}
+
+func funcs(pkgs []*srcutil.Package) (*table, error) {
+	t := &table{columns: []string{"package", "name", "signature"}}
+	for _, pkg := range pkgs {
+		api, err := pkg.API()
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range api.Funcs {
+			t.add(pkg.ImportPath, f.Name, f.Signature)
+		}
+	}
+	return t, nil
+}
+
+func methods(pkgs []*srcutil.Package, typeNames []string) (*table, error) {
+	t := &table{columns: []string{"package", "type", "recv", "name", "signature"}}
+	want := make(map[string]bool)
+	for _, typeName := range typeNames {
+		want[typeName] = true
+	}
+	for _, pkg := range pkgs {
+		api, err := pkg.API()
+		if err != nil {
+			return nil, err
+		}
+		for _, typ := range api.Types {
+			if len(want) > 0 && !want[typ.Name] {
+				continue
+			}
+			for _, m := range typ.Methods {
+				t.add(pkg.ImportPath, typ.Name, m.Recv, m.Name, m.Signature)
+			}
+		}
+	}
+	return t, nil
+}
+
+func structs(pkgs []*srcutil.Package) (*table, error) {
+	t := &table{columns: []string{"package", "struct", "field", "type", "tag"}}
+	for _, pkg := range pkgs {
+		ex, err := pkg.Export()
+		if err != nil {
+			return nil, err
+		}
+		for _, typ := range ex.Types {
+			if typ.Kind != "struct" {
+				continue
+			}
+			if len(typ.Fields) == 0 {
+				t.add(pkg.ImportPath, typ.Name, ``, ``, ``)
+			}
+			for _, f := range typ.Fields {
+				t.add(pkg.ImportPath, typ.Name, f.Name, f.Type, f.Tag)
+			}
+		}
+	}
+	return t, nil
+}
+
+func notes(pkgs []*srcutil.Package, marker, uid string, bare bool) (*table, error) {
+	t := &table{columns: []string{"package", "pos", "marker", "uid", "body"}}
+	filter := srcutil.NoteFilter{Bare: bare}
+	if len(marker) > 0 {
+		filter.Markers = []string{marker}
+	}
+	if len(uid) > 0 {
+		filter.UIDs = []string{uid}
+	}
+	notes, err := srcutil.CollectNotes(pkgs, filter)
+	if err != nil {
+		return nil, err
+	}
+	for _, note := range notes {
+		pos := fmt.Sprintf("%s:%d", filepath.Base(note.Pos.Filename), note.Pos.Line)
+		body := strings.Join(strings.Fields(note.Body), " ")
+		t.add(note.ImportPath, pos, note.Marker, note.UID, body)
+	}
+	return t, nil
+}
+
+func tags(w io.Writer, pkgs []*srcutil.Package, format string, etags bool) error {
+	dir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	symbols, err := srcutil.CollectSymbols(pkgs)
+	if err != nil {
+		return err
+	}
+	switch {
+	case format == "json":
+		return srcutil.WriteSymbols(w, symbols)
+	case format != "text":
+		return fmt.Errorf("unknown format %q, must be text or json", format)
+	case etags:
+		return srcutil.WriteETags(w, dir, symbols)
+	}
+	return srcutil.WriteTags(w, dir, symbols)
+}
+
+func files(pkgs []*srcutil.Package, tests bool) *table {
+	t := &table{columns: []string{"package", "path"}}
+	for _, pkg := range pkgs {
+		files := pkg.Files()
+		paths := files.SourcePaths()
+		if tests {
+			paths = files.TestPaths()
+		}
+		for _, path := range paths {
+			t.add(pkg.ImportPath, path)
+		}
+	}
+	return t
+}
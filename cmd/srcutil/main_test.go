@@ -0,0 +1,105 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const tpkg = "github.com/cstockton/go-srcutil/testdata"
+
+func trun(t *testing.T, args ...string) string {
+	var buf bytes.Buffer
+	if err := run(args, &buf); err != nil {
+		t.Fatal(err)
+	}
+	return buf.String()
+}
+
+func TestRun(t *testing.T) {
+	t.Run("Funcs", func(t *testing.T) {
+		got := trun(t, "funcs", "-format", "tsv", tpkg)
+		exp := tpkg + "\tStringFunc\tfunc(string) string\n"
+		if !strings.HasSuffix(got, exp) {
+			t.Errorf("exp suffix %q; got %q", exp, got)
+		}
+	})
+	t.Run("Methods", func(t *testing.T) {
+		got := trun(t, "methods", "-format", "tsv", "bufio", "Reader")
+		if !strings.Contains(got, "bufio\tReader\t*Reader\tReadRune\tfunc() (rune, int, error)\n") {
+			t.Errorf("exp bufio.Reader methods; got %q", got)
+		}
+		if strings.Contains(got, "\tWriter\t") {
+			t.Errorf("exp only bufio.Reader methods; got %q", got)
+		}
+	})
+	t.Run("Structs", func(t *testing.T) {
+		var got []map[string]string
+		out := trun(t, "structs", "-format", "json", tpkg+"/api/...")
+		if err := json.Unmarshal([]byte(out), &got); err != nil {
+			t.Fatal(err)
+		}
+		if len(got) == 0 || got[0]["struct"] != "Generic" || got[0]["field"] != "V" {
+			t.Errorf("exp Generic struct fields; got %v", got)
+		}
+	})
+	t.Run("Notes", func(t *testing.T) {
+		got := trun(t, "notes", "-format", "tsv", "-marker", "WORLD", "-uid", "chris", tpkg)
+		exp := tpkg + "\ttpkg_private.go:69\tWORLD\tchris\tNote world 2 for testing.\n"
+		if got != exp {
+			t.Errorf("exp %q; got %q", exp, got)
+		}
+
+		const notes = "github.com/cstockton/go-srcutil/testdata/notes"
+		got = trun(t, "notes", "-format", "tsv", "-bare", "-marker", "XXX", notes)
+		exp = notes + "\tnotes.go:33\tXXX\t\tbare note on a grouped const.\n"
+		if got != exp {
+			t.Errorf("exp %q; got %q", exp, got)
+		}
+	})
+	t.Run("Files", func(t *testing.T) {
+		got := trun(t, "files", "-tests", tpkg)
+		if !strings.Contains(got, "tpkg_example_test.go\n") {
+			t.Errorf("exp test files; got %q", got)
+		}
+		if strings.Contains(got, "tpkg.go\n") {
+			t.Errorf("exp only test files; got %q", got)
+		}
+	})
+	t.Run("Tags", func(t *testing.T) {
+		got := trun(t, "tags", tpkg+"/tags")
+		if !strings.Contains(got, "testdata/tags/tags.go\t27;\"\tkind:func\t") {
+			t.Errorf("exp Open tag; got %q", got)
+		}
+		got = trun(t, "tags", "-etags", tpkg+"/tags")
+		if !strings.Contains(got, "func Open\x7fOpen\x0127,") {
+			t.Errorf("exp Open etag; got %q", got)
+		}
+		got = trun(t, "tags", "-format", "json", tpkg+"/tags")
+		if !strings.Contains(got, `"signature": "(name string) (*File, error)"`) {
+			t.Errorf("exp Open symbol; got %q", got)
+		}
+	})
+	t.Run("LSIF", func(t *testing.T) {
+		got := trun(t, "lsif", tpkg+"/tags")
+		if !strings.HasPrefix(got, `{"id":1,"type":"vertex","label":"metaData"`) {
+			t.Errorf("exp LSIF metaData; got %q", got)
+		}
+		if !strings.Contains(got, `"identifier":"`+tpkg+`/tags:Open"`) {
+			t.Errorf("exp Open moniker; got %q", got)
+		}
+	})
+	t.Run("Failure", func(t *testing.T) {
+		var buf bytes.Buffer
+		for _, args := range [][]string{
+			{}, {"unknown"}, {"funcs", "-unknown"},
+			{"funcs", "-format", "xml", tpkg}, {"funcs", "thislibrarydoesntexist"},
+			{"tags", "-format", "tsv", tpkg},
+		} {
+			if err := run(args, &buf); err == nil {
+				t.Errorf("exp error for args %v", args)
+			}
+		}
+	})
+}
@@ -0,0 +1,133 @@
+package srcutil
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// CoverageCount is the number of exported identifiers that are documented out
+// of the total number of exported identifiers.
+type CoverageCount struct {
+	Documented int
+	Total      int
+}
+
+// Percent returns the percentage of documented identifiers, a CoverageCount
+// without any identifiers is fully documented.
+func (c CoverageCount) Percent() float64 {
+	if c.Total == 0 {
+		return 100
+	}
+	return float64(c.Documented) / float64(c.Total) * 100
+}
+
+// String implements fmt.Stringer.
+func (c CoverageCount) String() string {
+	return fmt.Sprintf("%.1f%% (%d/%d)", c.Percent(), c.Documented, c.Total)
+}
+
+// Coverage is the documentation coverage of a package as returned by
+// Docs.Coverage.
+type Coverage struct {
+	ImportPath string
+
+	// Kinds contains the coverage of each kind of identifier, which are
+	// "package", "const", "var", "func", "type", "method" and "field".
+	Kinds map[string]CoverageCount
+
+	// Violations are each missing or malformed doc comment.
+	Violations []Diagnostic
+}
+
+// Total returns the coverage of all kinds of identifiers.
+func (c Coverage) Total() CoverageCount {
+	var total CoverageCount
+	for _, count := range c.Kinds {
+		total.Documented += count.Documented
+		total.Total += count.Total
+	}
+	return total
+}
+
+// String implements fmt.Stringer.
+func (c Coverage) String() string {
+	kinds := make([]string, 0, len(c.Kinds))
+	for kind := range c.Kinds {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	s := fmt.Sprintf("%s: %v", c.ImportPath, c.Total())
+	for _, kind := range kinds {
+		s += fmt.Sprintf(", %s %v", kind, c.Kinds[kind])
+	}
+	return s
+}
+
+// Coverage reports how many of the exported consts, vars, funcs, types,
+// methods and fields of this package are documented, as well as a Diagnostic
+// for each of the following violations:
+//
+//   - the package comment is missing or does not begin with "Package name"
+//   - an exported identifier does not have a doc comment
+//   - a doc comment does not begin with the name of the identifier it
+//     documents, types may also begin with "A", "An" or "The"
+//
+// Consts and vars within a documented group, struct fields and interface
+// methods are not required to begin with their name.
+func (d *Docs) Coverage() Coverage {
+	c := Coverage{
+		ImportPath: d.Package.ImportPath,
+		Kinds:      make(map[string]CoverageCount),
+	}
+	report := func(decl docDecl, format string, args ...interface{}) {
+		pos := decl.DocPos
+		if len(decl.Doc) == 0 {
+			pos = decl.Pos
+		}
+		c.Violations = append(c.Violations, Diagnostic{
+			Pos: pos, Category: "doc", Message: fmt.Sprintf(format, args...)})
+	}
+
+	for _, decl := range d.decls() {
+		count := c.Kinds[decl.Kind]
+		count.Total++
+		if len(decl.Doc) > 0 {
+			count.Documented++
+		}
+		c.Kinds[decl.Kind] = count
+
+		switch {
+		case decl.Kind == "package" && len(decl.Doc) == 0:
+			report(decl, "missing package comment")
+		case decl.Kind == "package":
+			if !strings.HasPrefix(decl.Doc, "Package "+decl.Name+" ") {
+				report(decl, `package comment should be of the form "Package %s ..."`,
+					decl.Name)
+			}
+		case len(decl.Doc) == 0:
+			report(decl, "exported %s %s should have a comment", decl.Kind, decl.Name)
+		case !decl.Group && !docStartsWith(decl):
+			name := decl.Name[strings.LastIndex(decl.Name, ".")+1:]
+			report(decl, `comment on exported %s %s should be of the form "%s ..."`,
+				decl.Kind, decl.Name, name)
+		}
+	}
+	sortDiagnostics(c.Violations)
+	return c
+}
+
+func docStartsWith(decl docDecl) bool {
+	name := decl.Name[strings.LastIndex(decl.Name, ".")+1:]
+	text := decl.Doc
+	if decl.Kind == "type" {
+		for _, article := range []string{"A ", "An ", "The "} {
+			text = strings.TrimPrefix(text, article)
+		}
+	}
+	if !strings.HasPrefix(text, name) {
+		return false
+	}
+	rest := text[len(name):]
+	return len(rest) == 0 || strings.IndexAny(rest[:1], " \n\t.,:;'") == 0
+}
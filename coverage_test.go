@@ -0,0 +1,60 @@
+package srcutil
+
+import (
+	"testing"
+)
+
+func TestCoverage(t *testing.T) {
+	ctx := FromWorkDir()
+
+	t.Run("Coverage", func(t *testing.T) {
+		pkg, err := ctx.Import("github.com/cstockton/go-srcutil/testdata/coverage")
+		tmust(t, err)
+		docs := pkg.Docs()
+		got := docs.Coverage()
+		teq(t, map[string]CoverageCount{
+			"package": {1, 1},
+			"const":   {2, 2},
+			"var":     {1, 2},
+			"func":    {2, 3},
+			"type":    {1, 1},
+			"method":  {1, 2},
+			"field":   {1, 2},
+		}, got.Kinds)
+		teq(t, CoverageCount{9, 13}, got.Total())
+
+		var msgs []string
+		for _, d := range got.Violations {
+			teq(t, "doc", d.Category)
+			msgs = append(msgs, d.Message)
+		}
+		teq(t, []string{
+			`package comment should be of the form "Package coverage ..."`,
+			`exported func Undocumented should have a comment`,
+			`comment on exported func WrongName should be of the form "WrongName ..."`,
+			`exported field Widget.Size should have a comment`,
+			`exported method Widget.Undocumented should have a comment`,
+			`comment on exported var Single should be of the form "Single ..."`,
+			`exported var Bare should have a comment`,
+		}, msgs)
+		teq(t, 7, got.Violations[1].Pos.Line)
+	})
+	t.Run("Documented", func(t *testing.T) {
+		pkg, err := ctx.Import(tPkg.ImportPath)
+		tmust(t, err)
+		docs := pkg.Docs()
+		got := docs.Coverage()
+		teq(t, CoverageCount{0, 2}, got.Kinds["field"])
+		teq(t, 2, len(got.Violations))
+		for kind, count := range got.Kinds {
+			if kind != "field" {
+				teq(t, 100.0, count.Percent())
+			}
+		}
+	})
+	t.Run("Percent", func(t *testing.T) {
+		teq(t, 100.0, CoverageCount{}.Percent())
+		teq(t, 50.0, CoverageCount{1, 2}.Percent())
+		teq(t, "50.0% (1/2)", CoverageCount{1, 2}.String())
+	})
+}
@@ -0,0 +1,355 @@
+package srcutil
+
+import (
+	"fmt"
+	"go/build"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DepGraph is a directed graph of the import declarations between packages,
+// built from the build.Package.Imports of each package reachable from the
+// packages it was created with. Each node is keyed by its import path. It is
+// read only once created and safe for concurrent use from multiple Goroutines.
+type DepGraph struct {
+	roots   []string
+	pkgs    map[string]*build.Package
+	imports map[string][]string
+	rdeps   map[string][]string
+}
+
+// DepGraph returns a DepGraph containing each of the given packages and all of
+// the packages they transitively import, resolved as if they were imported
+// from the SourceDir of this Context.
+func (c *Context) DepGraph(pkgNames ...string) (*DepGraph, error) {
+	g := &DepGraph{
+		pkgs:    make(map[string]*build.Package),
+		imports: make(map[string][]string),
+		rdeps:   make(map[string][]string),
+	}
+	srcDir := defaultToGetwd(c.SourceDir)
+	for _, pkgName := range pkgNames {
+		buildPkg, err := c.Context.Import(pkgName, srcDir, DefaultImportMode)
+		if err != nil {
+			return nil, err
+		}
+		g.roots = append(g.roots, buildPkg.ImportPath)
+		if err = g.walk(c, buildPkg); err != nil {
+			return nil, err
+		}
+	}
+	for from, tos := range g.imports {
+		for _, to := range tos {
+			g.rdeps[to] = append(g.rdeps[to], from)
+		}
+	}
+	for to := range g.rdeps {
+		sort.Strings(g.rdeps[to])
+	}
+	return g, nil
+}
+
+func (g *DepGraph) walk(c *Context, buildPkg *build.Package) error {
+	if _, ok := g.pkgs[buildPkg.ImportPath]; ok {
+		return nil
+	}
+	g.pkgs[buildPkg.ImportPath] = buildPkg
+
+	var imports []string
+	for _, importPath := range buildPkg.Imports {
+		if importPath == "C" {
+			continue // cgo pseudo package
+		}
+		dep, err := c.Context.Import(importPath, buildPkg.Dir, DefaultImportMode)
+		if err != nil {
+			return fmt.Errorf(`unable to import "%s" from "%s": %v`,
+				importPath, buildPkg.ImportPath, err)
+		}
+		imports = append(imports, dep.ImportPath)
+		if err = g.walk(c, dep); err != nil {
+			return err
+		}
+	}
+	sort.Strings(imports)
+	g.imports[buildPkg.ImportPath] = imports
+	return nil
+}
+
+// String implements fmt.Stringer.
+func (g *DepGraph) String() string {
+	return fmt.Sprintf("DepGraph(%s)", strings.Join(g.roots, ", "))
+}
+
+// Len returns the number of packages within this DepGraph.
+func (g *DepGraph) Len() int {
+	return len(g.pkgs)
+}
+
+// Packages returns a sorted slice of the import paths of every package within
+// this DepGraph.
+func (g *DepGraph) Packages() []string {
+	out := make([]string, 0, len(g.pkgs))
+	for importPath := range g.pkgs {
+		out = append(out, importPath)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Package returns the build.Package for the given import path, or nil if it
+// is not within this DepGraph.
+func (g *DepGraph) Package(importPath string) *build.Package {
+	return g.pkgs[importPath]
+}
+
+// Imports returns a sorted slice of the import paths directly imported by the
+// given package.
+func (g *DepGraph) Imports(importPath string) []string {
+	return g.copyOf(g.imports[importPath])
+}
+
+// ImportedBy returns a sorted slice of the import paths of the packages that
+// directly import the given package.
+func (g *DepGraph) ImportedBy(importPath string) []string {
+	return g.copyOf(g.rdeps[importPath])
+}
+
+// Deps returns a sorted slice of the import paths of every package the given
+// package transitively imports, this is the transitive closure of Imports.
+func (g *DepGraph) Deps(importPath string) []string {
+	return g.closure(importPath, g.imports)
+}
+
+// Dependents returns a sorted slice of the import paths of every package that
+// transitively imports the given package, answering "who imports this".
+func (g *DepGraph) Dependents(importPath string) []string {
+	return g.closure(importPath, g.rdeps)
+}
+
+// ShortestPath returns the shortest chain of imports leading from one package
+// to another, including both ends. It returns nil when no such chain exists.
+func (g *DepGraph) ShortestPath(from, to string) []string {
+	if _, ok := g.pkgs[from]; !ok {
+		return nil
+	}
+	prev := map[string]string{from: ``}
+	queue := []string{from}
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		if cur == to {
+			var path []string
+			for ; cur != from; cur = prev[cur] {
+				path = append(path, cur)
+			}
+			path = append(path, from)
+			for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+				path[i], path[j] = path[j], path[i]
+			}
+			return path
+		}
+		for _, next := range g.imports[cur] {
+			if _, seen := prev[next]; !seen {
+				prev[next] = cur
+				queue = append(queue, next)
+			}
+		}
+	}
+	return nil
+}
+
+// Cycles returns each set of packages that import each other, directly or
+// indirectly. Each cycle is sorted and the cycles are ordered by their first
+// import path. The Go tool chain rejects import cycles, but the source code in
+// a directory may still contain them.
+func (g *DepGraph) Cycles() [][]string {
+	// Tarjan's strongly connected components algorithm.
+	var (
+		index   int
+		stack   []string
+		cycles  [][]string
+		indices = make(map[string]int)
+		lowlink = make(map[string]int)
+		onStack = make(map[string]bool)
+		connect func(v string)
+	)
+	connect = func(v string) {
+		indices[v], lowlink[v] = index, index
+		index++
+		stack = append(stack, v)
+		onStack[v] = true
+		for _, w := range g.imports[v] {
+			if _, ok := indices[w]; !ok {
+				connect(w)
+				if lowlink[w] < lowlink[v] {
+					lowlink[v] = lowlink[w]
+				}
+			} else if onStack[w] && indices[w] < lowlink[v] {
+				lowlink[v] = indices[w]
+			}
+		}
+		if lowlink[v] != indices[v] {
+			return
+		}
+		var scc []string
+		for {
+			w := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[w] = false
+			scc = append(scc, w)
+			if w == v {
+				break
+			}
+		}
+		if len(scc) > 1 {
+			sort.Strings(scc)
+			cycles = append(cycles, scc)
+		}
+	}
+	for _, v := range g.Packages() {
+		if _, ok := indices[v]; !ok {
+			connect(v)
+		}
+	}
+	sort.Slice(cycles, func(i, j int) bool {
+		return cycles[i][0] < cycles[j][0]
+	})
+	return cycles
+}
+
+// Rule forbids packages matching From from importing packages matching To.
+// Patterns are import paths that may contain the "..." wildcard as described
+// by "go help packages", I.E.:
+//
+//	Rule{From: "example.com/app/internal/domain/...", To: "net/http"}
+type Rule struct {
+	From string
+	To   string
+
+	// Transitive causes the rule to also forbid indirect imports.
+	Transitive bool
+
+	// Reason is an optional explanation included in each Violation.
+	Reason string
+}
+
+// String implements fmt.Stringer.
+func (r Rule) String() string {
+	op := "->"
+	if r.Transitive {
+		op = "->*"
+	}
+	return fmt.Sprintf("%s %s %s", r.From, op, r.To)
+}
+
+// Violation is a forbidden import found by DepGraph.Check.
+type Violation struct {
+	Rule Rule
+
+	// Path is the chain of imports from the offending package to the forbidden
+	// package. It will only be longer than two for Transitive rules.
+	Path []string
+}
+
+// String implements fmt.Stringer.
+func (v Violation) String() string {
+	s := fmt.Sprintf("%s: forbidden by rule %v", strings.Join(v.Path, " -> "), v.Rule)
+	if len(v.Rule.Reason) > 0 {
+		s += ": " + v.Rule.Reason
+	}
+	return s
+}
+
+// Check returns a Violation for each import within this DepGraph that is
+// forbidden by the given rules. Violations are ordered by rule and then by
+// the import path of the offending package.
+func (g *DepGraph) Check(rules ...Rule) []Violation {
+	var violations []Violation
+	for _, rule := range rules {
+		fromMatch, toMatch := matchPattern(rule.From), matchPattern(rule.To)
+		for _, from := range g.Packages() {
+			if !fromMatch(from) {
+				continue
+			}
+			targets := g.imports[from]
+			if rule.Transitive {
+				targets = g.Deps(from)
+			}
+			for _, to := range targets {
+				if !toMatch(to) {
+					continue
+				}
+				v := Violation{Rule: rule, Path: []string{from, to}}
+				if rule.Transitive {
+					v.Path = g.ShortestPath(from, to)
+				}
+				violations = append(violations, v)
+			}
+		}
+	}
+	return violations
+}
+
+// WriteDot writes this DepGraph to w in the Graphviz DOT language.
+func (g *DepGraph) WriteDot(w io.Writer) error {
+	if _, err := fmt.Fprintln(w, "digraph deps {"); err != nil {
+		return err
+	}
+	for _, from := range g.Packages() {
+		if _, err := fmt.Fprintf(w, "\t%q;\n", from); err != nil {
+			return err
+		}
+		for _, to := range g.imports[from] {
+			if _, err := fmt.Fprintf(w, "\t%q -> %q;\n", from, to); err != nil {
+				return err
+			}
+		}
+	}
+	_, err := fmt.Fprintln(w, "}")
+	return err
+}
+
+func (g *DepGraph) copyOf(s []string) []string {
+	out := make([]string, len(s))
+	copy(out, s)
+	return out
+}
+
+func (g *DepGraph) closure(importPath string, edges map[string][]string) []string {
+	seen := map[string]bool{importPath: true}
+	queue := []string{importPath}
+	var out []string
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		for _, next := range edges[cur] {
+			if !seen[next] {
+				seen[next] = true
+				out = append(out, next)
+				queue = append(queue, next)
+			}
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// matchPattern is from:
+//
+//	https://golang.org/src/cmd/go/internal/search/search.go
+//
+// matchPattern(pattern)(name) reports whether name matches pattern. Pattern
+// is a limited glob pattern in which '...' means 'any string' and there is no
+// other special syntax.
+func matchPattern(pattern string) func(name string) bool {
+	re := regexp.QuoteMeta(pattern)
+	re = strings.Replace(re, `\.\.\.`, `.*`, -1)
+	// Special case: foo/... matches foo too.
+	if strings.HasSuffix(re, `/.*`) {
+		re = re[:len(re)-len(`/.*`)] + `(/.*)?`
+	}
+	reg := regexp.MustCompile(`^` + re + `$`)
+	return reg.MatchString
+}
@@ -0,0 +1,102 @@
+package srcutil
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDepGraph(t *testing.T) {
+	const deps = "github.com/cstockton/go-srcutil/testdata/deps/"
+	ctx := FromWorkDir()
+	g, err := ctx.DepGraph(deps+"app", deps+"cyca")
+	tmust(t, err)
+
+	t.Run("Packages", func(t *testing.T) {
+		teq(t, true, g.Len() > 5)
+		teq(t, true, g.Package(deps+"app") != nil)
+		teq(t, true, g.Package("net/http") != nil)
+		teq(t, true, g.Package("thispackagedoesntexist") == nil)
+	})
+	t.Run("Imports", func(t *testing.T) {
+		teq(t, []string{deps + "domain", deps + "store"}, g.Imports(deps+"app"))
+		teq(t, []string{"strings"}, g.Imports(deps+"store"))
+	})
+	t.Run("ImportedBy", func(t *testing.T) {
+		teq(t, []string{deps + "app"}, g.ImportedBy(deps+"domain"))
+		teq(t, true, len(g.ImportedBy("strings")) > 1)
+	})
+	t.Run("Deps", func(t *testing.T) {
+		got := g.Deps(deps + "app")
+		teq(t, true, len(got) > 3)
+		for _, exp := range []string{deps + "domain", deps + "store", "net/http", "strings"} {
+			if !hasString(got, exp) {
+				t.Errorf("expected %v in deps of app", exp)
+			}
+		}
+	})
+	t.Run("Dependents", func(t *testing.T) {
+		teq(t, []string{deps + "app", deps + "domain"}, g.Dependents("net/http"))
+	})
+	t.Run("ShortestPath", func(t *testing.T) {
+		teq(t, []string{deps + "app", deps + "domain", "net/http"},
+			g.ShortestPath(deps+"app", "net/http"))
+		teq(t, []string{deps + "app"}, g.ShortestPath(deps+"app", deps+"app"))
+		if got := g.ShortestPath(deps+"store", deps+"app"); got != nil {
+			t.Errorf("expected nil path from store to app; got %v", got)
+		}
+	})
+	t.Run("Cycles", func(t *testing.T) {
+		teq(t, [][]string{{deps + "cyca", deps + "cycb"}}, g.Cycles())
+	})
+	t.Run("Check", func(t *testing.T) {
+		direct := Rule{From: deps + "...", To: "net/http", Reason: "layering"}
+		got := g.Check(direct)
+		teq(t, 1, len(got))
+		teq(t, []string{deps + "domain", "net/http"}, got[0].Path)
+		teq(t, true, strings.HasSuffix(got[0].String(), ": layering"))
+
+		transitive := Rule{From: deps + "app", To: "net/http", Transitive: true}
+		got = g.Check(transitive)
+		teq(t, 1, len(got))
+		teq(t, []string{deps + "app", deps + "domain", "net/http"}, got[0].Path)
+
+		teq(t, 0, len(g.Check(Rule{From: deps + "store", To: "net/http"})))
+	})
+	t.Run("WriteDot", func(t *testing.T) {
+		var buf bytes.Buffer
+		tmust(t, g.WriteDot(&buf))
+		got := buf.String()
+		teq(t, true, strings.HasPrefix(got, "digraph deps {\n"))
+		teq(t, true, strings.Contains(got, `"`+deps+`app" -> "`+deps+`domain";`))
+	})
+	t.Run("Failure", func(t *testing.T) {
+		g, err := ctx.DepGraph("thislibrarydoesntexist")
+		if err == nil {
+			t.Errorf("expected error for non-existent import")
+		}
+		if g != nil {
+			t.Errorf("expected nil DepGraph for non-existent import")
+		}
+	})
+}
+
+func TestMatchPattern(t *testing.T) {
+	tests := []struct {
+		pattern, name string
+		exp           bool
+	}{
+		{"net/http", "net/http", true},
+		{"net/http", "net/http/httptest", false},
+		{"net/...", "net", true},
+		{"net/...", "net/http", true},
+		{"net/...", "netx", false},
+		{"...domain", "a/b/domain", true},
+	}
+	for _, test := range tests {
+		if got := matchPattern(test.pattern)(test.name); got != test.exp {
+			t.Errorf("matchPattern(%q)(%q): exp %v; got %v",
+				test.pattern, test.name, test.exp, got)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package srcutil
+
+import (
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+)
+
+// IsDeprecated returns true if the doc comment has a "Deprecated: " paragraph.
+func (f Func) IsDeprecated() bool {
+	_, ok := deprecationNote(f.Doc)
+	return ok
+}
+
+// DeprecationNote returns the text following "Deprecated: " in the doc comment.
+func (f Func) DeprecationNote() string {
+	note, _ := deprecationNote(f.Doc)
+	return note
+}
+
+// IsDeprecated returns true if the doc comment has a "Deprecated: " paragraph.
+func (v Var) IsDeprecated() bool {
+	_, ok := deprecationNote(v.Doc)
+	return ok
+}
+
+// DeprecationNote returns the text following "Deprecated: " in the doc comment.
+func (v Var) DeprecationNote() string {
+	note, _ := deprecationNote(v.Doc)
+	return note
+}
+
+// IsDeprecated returns true if the doc comment has a "Deprecated: " paragraph.
+func (s Struct) IsDeprecated() bool {
+	_, ok := deprecationNote(s.Doc)
+	return ok
+}
+
+// DeprecationNote returns the text following "Deprecated: " in the doc comment.
+func (s Struct) DeprecationNote() string {
+	note, _ := deprecationNote(s.Doc)
+	return note
+}
+
+// IsDeprecated returns true if the doc comment of the named type has a
+// "Deprecated: " paragraph, the methods may be deprecated individually.
+func (m MethodSet) IsDeprecated() bool {
+	_, ok := deprecationNote(m.Doc)
+	return ok
+}
+
+// DeprecationNote returns the text following "Deprecated: " in the doc comment.
+func (m MethodSet) DeprecationNote() string {
+	note, _ := deprecationNote(m.Doc)
+	return note
+}
+
+// deprecationNote returns the paragraph of a doc comment beginning with
+// "Deprecated: " as described in https://go.dev/wiki/Deprecated, without the
+// prefix and with the lines joined.
+func deprecationNote(text string) (string, bool) {
+	for _, para := range strings.Split(text, "\n\n") {
+		para = strings.TrimSpace(para)
+		if strings.HasPrefix(para, "Deprecated: ") {
+			return strings.Join(strings.Fields(para[len("Deprecated:"):]), " "), true
+		}
+	}
+	return ``, false
+}
+
+// Deprecation is a deprecated declaration.
+type Deprecation struct {
+	// ImportPath is the package the declaration belongs to.
+	ImportPath string
+
+	// Name of the declaration, methods and fields are named "Type.Name".
+	Name string
+
+	// Kind is one of "package", "const", "var", "func", "type", "method" or
+	// "field".
+	Kind string
+	Note string
+	Pos  token.Position
+}
+
+// Deprecations returns each declaration of this package whose doc comment has
+// a "Deprecated: " paragraph, including the package itself.
+func (d *Docs) Deprecations() []Deprecation {
+	var out []Deprecation
+	for _, decl := range d.decls() {
+		if note, ok := deprecationNote(decl.Doc); ok {
+			out = append(out, Deprecation{
+				ImportPath: d.Package.ImportPath, Name: decl.Name,
+				Kind: decl.Kind, Note: note, Pos: decl.Pos})
+		}
+	}
+	return out
+}
+
+// DeprecatedUse is a reference to a deprecated declaration.
+type DeprecatedUse struct {
+	Deprecation Deprecation
+
+	// Package is the package the reference was found in.
+	Package *Package
+	Pos     token.Position
+}
+
+// DeprecatedUses returns every reference within the given packages to the
+// deprecated declarations of the given packages, ordered by position. Only
+// declarations of the given packages are considered, so you should include
+// the packages whose deprecations you are interested in. References from
+// within the package that declares the deprecation are not reported.
+func DeprecatedUses(pkgs []*Package) []DeprecatedUse {
+	byName := make(map[string]Deprecation)
+	byPos := make(map[token.Position]Deprecation)
+	for _, pkg := range pkgs {
+		docs := pkg.Docs()
+		for _, dep := range docs.Deprecations() {
+			byName[pkg.ImportPath+"."+dep.Name] = dep
+			byPos[dep.Pos] = dep
+		}
+	}
+
+	var out []DeprecatedUse
+	for _, pkg := range pkgs {
+		for ident, obj := range pkg.tc.typesInfo.Uses {
+			if obj.Pkg() == nil {
+				continue
+			}
+			dep, ok := byName[objKey(obj)]
+			if v, isVar := obj.(*types.Var); isVar && v.IsField() {
+				// Fields do not know the struct they belong to, so they are found
+				// by the position of their declaration instead.
+				dep, ok = byPos[pkg.tc.fileSet.Position(obj.Pos())]
+				ok = ok && objKey(obj) == dep.ImportPath+"."+obj.Name()
+			}
+			if ok && dep.ImportPath != pkg.ImportPath {
+				out = append(out, DeprecatedUse{Deprecation: dep, Package: pkg,
+					Pos: pkg.tc.fileSet.Position(ident.Pos())})
+			}
+		}
+		for _, f := range pkg.tc.astPkg.Files {
+			for _, imp := range f.Imports {
+				path := strings.Trim(imp.Path.Value, "`\"")
+				for _, other := range pkgs {
+					if other.ImportPath != path {
+						continue
+					}
+					dep, ok := byName[path+"."+other.Name]
+					if ok && dep.Kind == "package" {
+						out = append(out, DeprecatedUse{Deprecation: dep, Package: pkg,
+							Pos: pkg.tc.fileSet.Position(imp.Pos())})
+					}
+				}
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i].Pos, out[j].Pos
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		return a.Offset < b.Offset
+	})
+	return out
+}
+
+// objKey returns the name of obj qualified by its import path, methods are
+// also qualified by their receiver type, I.E. "io.Reader.Read".
+func objKey(obj types.Object) string {
+	path := obj.Pkg().Path()
+	if f, ok := obj.(*types.Func); ok {
+		if recv := f.Type().(*types.Signature).Recv(); recv != nil {
+			typ := recv.Type()
+			if ptr, ok := typ.(*types.Pointer); ok {
+				typ = ptr.Elem()
+			}
+			if named, ok := typ.(*types.Named); ok {
+				return path + "." + named.Obj().Name() + "." + obj.Name()
+			}
+		}
+	}
+	return path + "." + obj.Name()
+}
@@ -0,0 +1,78 @@
+package srcutil
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestDeprecated(t *testing.T) {
+	const deprecated = "github.com/cstockton/go-srcutil/testdata/deprecated/"
+	ctx := FromWorkDir()
+	pkgs, err := ctx.ImportAll(deprecated+"old", deprecated+"user")
+	tmust(t, err)
+	old := pkgs[0]
+
+	t.Run("Wrappers", func(t *testing.T) {
+		for _, f := range old.Funcs() {
+			teq(t, f.Name() == "Legacy", f.IsDeprecated())
+			if f.Name() == "Legacy" {
+				teq(t, "Use Current instead.", f.DeprecationNote())
+			}
+		}
+		vars := old.Vars()
+		teq(t, true, vars[0].IsDeprecated())
+		teq(t, "Use Current.", vars[0].DeprecationNote())
+
+		structs := old.Structs()
+		teq(t, true, structs[0].IsDeprecated())
+		teq(t, "Use Options.", structs[0].DeprecationNote())
+
+		ms, err := old.MethodSet("Config")
+		tmust(t, err)
+		teq(t, true, ms.IsDeprecated())
+		teq(t, true, ms.Methods["Run"].IsDeprecated())
+		teq(t, "Use Start.", ms.Methods["Run"].DeprecationNote())
+		teq(t, false, ms.Methods["Start"].IsDeprecated())
+		teq(t, ``, ms.Methods["Start"].DeprecationNote())
+	})
+	t.Run("Deprecations", func(t *testing.T) {
+		docs := old.Docs()
+		var got []string
+		for _, dep := range docs.Deprecations() {
+			got = append(got, fmt.Sprintf("%s %s: %s", dep.Kind, dep.Name, dep.Note))
+		}
+		teq(t, []string{
+			"package old: use package user instead.",
+			"var Default: Use Current.",
+			"func Legacy: Use Current instead.",
+			"type Config: Use Options.",
+			"field Config.Name: Use Label.",
+			"method Config.Run: Use Start.",
+		}, got)
+	})
+	t.Run("DeprecatedUses", func(t *testing.T) {
+		var got []string
+		for _, use := range DeprecatedUses(pkgs) {
+			teq(t, pkgs[1], use.Package)
+			got = append(got, fmt.Sprintf("%s:%d %s",
+				filepath.Base(use.Pos.Filename), use.Pos.Line, use.Deprecation.Name))
+		}
+		teq(t, []string{
+			"user.go:4 old",
+			"user.go:8 Legacy",
+			"user.go:10 Config",
+			"user.go:10 Config.Name",
+			"user.go:11 Config.Run",
+			"user.go:13 Default",
+			"user.go:13 Config.Name",
+		}, got)
+	})
+	t.Run("DeprecationNote", func(t *testing.T) {
+		_, ok := deprecationNote("Foo does things.\n\nDeprecated:no space.")
+		teq(t, false, ok)
+		note, ok := deprecationNote("Deprecated: first paragraph.\n\nMore.")
+		teq(t, true, ok)
+		teq(t, "first paragraph.", note)
+	})
+}
@@ -0,0 +1,40 @@
+package srcutil
+
+import (
+	"fmt"
+	"go/token"
+	"sort"
+)
+
+// Diagnostic is a problem found at a position within a package.
+type Diagnostic struct {
+	Pos token.Position
+
+	// Category is a short name for the kind of problem, I.E. "doclink".
+	Category string
+	Message  string
+}
+
+// String implements fmt.Stringer.
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%v: %s (%s)", d.Pos, d.Message, d.Category)
+}
+
+// sortDiagnostics sorts diagnostics by position and then message.
+func sortDiagnostics(s []Diagnostic) {
+	sort.SliceStable(s, func(i, j int) bool {
+		return diagnosticLess(s[i], s[j])
+	})
+}
+
+// diagnosticLess reports whether a is ordered before b by position and then
+// message.
+func diagnosticLess(a, b Diagnostic) bool {
+	if a.Pos.Filename != b.Pos.Filename {
+		return a.Pos.Filename < b.Pos.Filename
+	}
+	if a.Pos.Offset != b.Pos.Offset {
+		return a.Pos.Offset < b.Pos.Offset
+	}
+	return a.Message < b.Message
+}
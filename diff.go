@@ -0,0 +1,128 @@
+package srcutil
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// lineDiff returns the lines of a and b prefixed with "-" when only in a, "+"
+// when only in b and " " when in both, or an empty string when a and b are
+// equal. The diff is found with the longest common subsequence of lines, which
+// is plenty fast for the size of source files and example output.
+func lineDiff(a, b string) string {
+	if a == b {
+		return ``
+	}
+	x, y := splitLines(a), splitLines(b)
+
+	// lcs[i][j] is the length of the longest common subsequence of x[i:] and
+	// y[j:].
+	lcs := make([][]int, len(x)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			switch {
+			case x[i] == y[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	i, j := 0, 0
+	for i < len(x) || j < len(y) {
+		switch {
+		case i < len(x) && j < len(y) && x[i] == y[j]:
+			buf.WriteString(" " + x[i] + "\n")
+			i, j = i+1, j+1
+		case j == len(y) || (i < len(x) && lcs[i+1][j] >= lcs[i][j+1]):
+			buf.WriteString("-" + x[i] + "\n")
+			i++
+		default:
+			buf.WriteString("+" + y[j] + "\n")
+			j++
+		}
+	}
+	return buf.String()
+}
+
+func splitLines(s string) []string {
+	if len(s) == 0 {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// unifiedDiff returns the changes from a to b in the unified format with
+// three lines of context, or an empty string when a and b are equal. Both are
+// named by path in the header.
+func unifiedDiff(path, a, b string) string {
+	diff := splitLines(lineDiff(a, b))
+	if len(diff) == 0 {
+		return ``
+	}
+	const context = 3
+
+	var buf bytes.Buffer
+	buf.WriteString("--- a/" + path + "\n+++ b/" + path + "\n")
+	for i := 0; i < len(diff); {
+		if diff[i][0] == ' ' {
+			i++
+			continue
+		}
+
+		// extend the hunk while the next change is within twice the context
+		start, end := i-context, i
+		for j := i; j < len(diff) && j-end <= 2*context+1; j++ {
+			if diff[j][0] != ' ' {
+				end = j
+			}
+		}
+		if start < 0 {
+			start = 0
+		}
+		end += context + 1
+		if end > len(diff) {
+			end = len(diff)
+		}
+
+		// line numbers of the first line of the hunk within a and b
+		aLine, bLine := 1, 1
+		for _, line := range diff[:start] {
+			if line[0] != '+' {
+				aLine++
+			}
+			if line[0] != '-' {
+				bLine++
+			}
+		}
+		var aCount, bCount int
+		for _, line := range diff[start:end] {
+			if line[0] != '+' {
+				aCount++
+			}
+			if line[0] != '-' {
+				bCount++
+			}
+		}
+		if aCount == 0 {
+			aLine--
+		}
+		if bCount == 0 {
+			bLine--
+		}
+		fmt.Fprintf(&buf, "@@ -%d,%d +%d,%d @@\n", aLine, aCount, bLine, bCount)
+		for _, line := range diff[start:end] {
+			buf.WriteString(line + "\n")
+		}
+		i = end
+	}
+	return buf.String()
+}
@@ -0,0 +1,188 @@
+package srcutil
+
+import (
+	"fmt"
+	"go/doc/comment"
+	"go/importer"
+	"go/token"
+	"go/types"
+)
+
+// DocComment is a doc comment parsed by the go/doc/comment package, which
+// provides its headings, lists, code blocks and links. Each doc link such as
+// [io.Reader] or [Package.Docs] within the comment is resolved using go/types.
+type DocComment struct {
+	*comment.Doc
+	Links []DocLink
+}
+
+// DocLink is a doc link within a DocComment.
+type DocLink struct {
+	*comment.DocLink
+
+	// Pkg is the package the link refers to and Obj the object within it, Obj
+	// is nil for links to a package such as [io]. Both are nil when the link
+	// could not be resolved.
+	Pkg *types.Package
+	Obj types.Object
+
+	// Err describes why the link could not be resolved.
+	Err error
+}
+
+// Broken returns true if the link could not be resolved.
+func (l DocLink) Broken() bool {
+	return l.Err != nil
+}
+
+// String implements fmt.Stringer.
+func (l DocLink) String() string {
+	s := "[" + docLinkName(l.DocLink) + "]"
+	if len(l.ImportPath) > 0 {
+		s = "[" + l.ImportPath
+		if name := docLinkName(l.DocLink); len(name) > 0 {
+			s += "." + name
+		}
+		s += "]"
+	}
+	return s
+}
+
+// Comment parses the given doc comment text, as found within the Doc fields
+// of the go/doc types, in the context of this package and resolves its links.
+//
+// Links to exported identifiers are recognized even when they do not exist,
+// so they may be reported as broken rather than silently rendered as text. An
+// error is returned when the package could not be type checked.
+func (d *Docs) Comment(text string) (DocComment, error) {
+	r, err := newDocLinkResolver(d.Package)
+	if err != nil {
+		return DocComment{}, err
+	}
+	return r.parse(text), nil
+}
+
+// BrokenLinks returns a Diagnostic for each doc link within the package and
+// declaration comments of this package which can not be resolved.
+func (d *Docs) BrokenLinks() ([]Diagnostic, error) {
+	r, err := newDocLinkResolver(d.Package)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[token.Position]bool)
+	var out []Diagnostic
+	for _, decl := range d.decls() {
+		if len(decl.Doc) == 0 || seen[decl.DocPos] {
+			continue
+		}
+		seen[decl.DocPos] = true
+		for _, link := range r.parse(decl.Doc).Links {
+			if link.Broken() {
+				out = append(out, Diagnostic{
+					Pos: decl.DocPos, Category: "doclink",
+					Message: fmt.Sprintf("broken doc link %v in comment for %s %s: %v",
+						link, decl.Kind, decl.Name, link.Err)})
+			}
+		}
+	}
+	sortDiagnostics(out)
+	return out, nil
+}
+
+type docLinkResolver struct {
+	pkg     *Package
+	parser  *comment.Parser
+	imports map[string]*types.Package
+}
+
+func newDocLinkResolver(p *Package) (*docLinkResolver, error) {
+	if err := p.init(); err != nil {
+		return nil, err
+	}
+	r := &docLinkResolver{pkg: p, imports: make(map[string]*types.Package)}
+	for _, imp := range p.tc.typesPkg.Imports() {
+		r.imports[imp.Path()] = imp
+	}
+	r.parser = p.tc.docPkg.Parser()
+	r.parser.LookupSym = func(recv, name string) bool {
+		return token.IsExported(name) && (len(recv) == 0 || token.IsExported(recv))
+	}
+	return r, nil
+}
+
+func (r *docLinkResolver) parse(text string) DocComment {
+	dc := DocComment{Doc: r.parser.Parse(text)}
+	var walk func(texts []comment.Text)
+	walk = func(texts []comment.Text) {
+		for _, t := range texts {
+			switch t := t.(type) {
+			case *comment.DocLink:
+				dc.Links = append(dc.Links, r.resolve(t))
+			case *comment.Link:
+				walk(t.Text)
+			}
+		}
+	}
+	for _, block := range dc.Content {
+		switch b := block.(type) {
+		case *comment.Paragraph:
+			walk(b.Text)
+		case *comment.Heading:
+			walk(b.Text)
+		case *comment.List:
+			for _, item := range b.Items {
+				for _, block := range item.Content {
+					if para, ok := block.(*comment.Paragraph); ok {
+						walk(para.Text)
+					}
+				}
+			}
+		}
+	}
+	return dc
+}
+
+func (r *docLinkResolver) resolve(link *comment.DocLink) DocLink {
+	dl := DocLink{DocLink: link}
+	dl.Pkg, dl.Err = r.lookupPackage(link.ImportPath)
+	if dl.Err != nil || len(link.Name) == 0 {
+		return dl
+	}
+
+	scope := dl.Pkg.Scope()
+	if len(link.Recv) == 0 {
+		if dl.Obj = scope.Lookup(link.Name); dl.Obj == nil {
+			dl.Err = fmt.Errorf("%s not declared by package %s", link.Name, dl.Pkg.Name())
+		}
+		return dl
+	}
+
+	recv, ok := scope.Lookup(link.Recv).(*types.TypeName)
+	if !ok {
+		dl.Err = fmt.Errorf("type %s not declared by package %s", link.Recv, dl.Pkg.Name())
+		return dl
+	}
+	obj, _, _ := types.LookupFieldOrMethod(types.NewPointer(recv.Type()), true, dl.Pkg, link.Name)
+	if obj == nil {
+		dl.Err = fmt.Errorf("type %s has no field or method %s", link.Recv, link.Name)
+	}
+	dl.Obj = obj
+	return dl
+}
+
+func (r *docLinkResolver) lookupPackage(importPath string) (*types.Package, error) {
+	if len(importPath) == 0 || importPath == r.pkg.ImportPath {
+		return r.pkg.tc.typesPkg, nil
+	}
+	if pkg, ok := r.imports[importPath]; ok {
+		return pkg, nil
+	}
+
+	// Doc links may refer to standard library packages that are not imported.
+	pkg, err := importer.Default().Import(importPath)
+	if err != nil {
+		return nil, fmt.Errorf("unable to import %q: %v", importPath, err)
+	}
+	r.imports[importPath] = pkg
+	return pkg, nil
+}
@@ -0,0 +1,71 @@
+package srcutil
+
+import (
+	"go/doc/comment"
+	"go/types"
+	"strings"
+	"testing"
+)
+
+func TestDocLinks(t *testing.T) {
+	ctx := FromWorkDir()
+	pkg, err := ctx.Import("github.com/cstockton/go-srcutil/testdata/doclinks")
+	tmust(t, err)
+	docs := pkg.Docs()
+
+	t.Run("Comment", func(t *testing.T) {
+		docPkg, err := pkg.ToDoc()
+		tmust(t, err)
+		dc, err := docs.Comment(docPkg.Doc)
+		tmust(t, err)
+		teq(t, true, len(dc.Content) > 3)
+		var kinds []string
+		for _, block := range dc.Content {
+			switch block.(type) {
+			case *comment.Heading:
+				kinds = append(kinds, "heading")
+			case *comment.List:
+				kinds = append(kinds, "list")
+			case *comment.Code:
+				kinds = append(kinds, "code")
+			}
+		}
+		teq(t, []string{"heading", "list", "code"}, kinds)
+
+		var links []string
+		for _, link := range dc.Links {
+			links = append(links, link.String())
+			if link.Broken() {
+				t.Errorf("exp link %v to resolve; got %v", link, link.Err)
+			}
+		}
+		teq(t, []string{"[Good]", "[io.Reader]", "[Good.Method]", "[Good.Field]",
+			"[strings.Builder]", "[fmt]"}, links)
+
+		teq(t, "Good", dc.Links[0].Obj.Name())
+		teq(t, "io", dc.Links[1].Obj.Pkg().Path())
+		teq(t, "Method", dc.Links[2].Obj.Name())
+		_, isVar := dc.Links[3].Obj.(*types.Var)
+		teq(t, true, isVar)
+		teq(t, "strings", dc.Links[4].Pkg.Path())
+		teq(t, "fmt", dc.Links[5].Pkg.Path())
+		teq(t, nil, dc.Links[5].Obj)
+	})
+	t.Run("BrokenLinks", func(t *testing.T) {
+		got, err := docs.BrokenLinks()
+		tmust(t, err)
+		var msgs []string
+		for _, d := range got {
+			teq(t, "doclink", d.Category)
+			teq(t, true, strings.HasSuffix(d.Pos.Filename, "doclinks.go"))
+			msgs = append(msgs, d.Message)
+		}
+		teq(t, []string{
+			"broken doc link [Good.Missing] in comment for type Good: type Good has no field or method Missing",
+			"broken doc link [Missing] in comment for type Good: Missing not declared by package doclinks",
+			"broken doc link [io.Missing] in comment for type Good: Missing not declared by package io",
+			"broken doc link [Nope] in comment for method Good.Method: Nope not declared by package doclinks",
+		}, msgs)
+		teq(t, 25, got[3].Pos.Line)
+	})
+}
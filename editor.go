@@ -0,0 +1,452 @@
+package srcutil
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Editor edits the files of a Package. Edits are recorded as replacements of
+// the source between two positions of the original files and are applied
+// when the files are printed by Source, Diff or Write, so the AST and type
+// information of an Editor remain valid while it is being edited. Only the
+// source that is replaced is changed, the comments of the files are
+// preserved and each edited file is gofmt'd.
+//
+// The files of an Editor are those of the package including its internal
+// tests, external test packages are not included. An Editor is not safe for
+// concurrent use and should be discarded after calling Write.
+type Editor struct {
+	Package *Package
+	Fset    *token.FileSet
+	Types   *types.Package
+	Info    *types.Info
+
+	// DryRun prevents Write from writing the edited files.
+	DryRun bool
+
+	files []*ast.File
+	src   map[string][]byte
+	edits map[string][]edit
+}
+
+type edit struct {
+	pos, end int
+	text     string
+}
+
+// Editor returns an Editor with a newly parsed and type checked AST of this
+// package.
+func (p *Package) Editor() (*Editor, error) {
+	if err := p.init(); err != nil {
+		return nil, err
+	}
+	tc, err := p.toToolchain(p.typesInfo())
+	if err != nil {
+		return nil, err
+	}
+	return newEditor(p, tc.fileSet, tc.typesPkg, tc.typesInfo, p.astFiles(tc.astPkg))
+}
+
+// xtestEditor returns an Editor of the files of the external _test package of
+// this package, or nil when it has none.
+func (p *Package) xtestEditor() (*Editor, error) {
+	if err := p.init(); err != nil {
+		return nil, err
+	}
+	if p.tc.xtestPkg == nil {
+		return nil, nil
+	}
+	tc, err := p.toToolchain(p.typesInfo())
+	if err != nil {
+		return nil, err
+	}
+	typesPkg, info, err := p.xtestCheck(tc)
+	if err != nil {
+		return nil, err
+	}
+	return newEditor(p, tc.fileSet, typesPkg, info, p.astFiles(tc.xtestPkg))
+}
+
+func newEditor(
+	p *Package, fset *token.FileSet, typesPkg *types.Package, info *types.Info, files []*ast.File,
+) (*Editor, error) {
+	e := &Editor{
+		Package: p, Fset: fset, Types: typesPkg, Info: info,
+		files: files,
+		src:   make(map[string][]byte),
+		edits: make(map[string][]edit),
+	}
+	sort.Slice(e.files, func(i, j int) bool {
+		return e.filename(e.files[i]) < e.filename(e.files[j])
+	})
+	for _, f := range e.files {
+		var err error
+		name := e.filename(f)
+		if e.src[name], err = os.ReadFile(name); err != nil {
+			return nil, err
+		}
+	}
+	return e, nil
+}
+
+func (e *Editor) filename(f *ast.File) string {
+	return e.Fset.Position(f.Package).Filename
+}
+
+// Files returns the files of the package ordered by name.
+func (e *Editor) Files() []*ast.File {
+	return append([]*ast.File(nil), e.files...)
+}
+
+// File returns the file with the given path or base name, or nil if the
+// package has no such file.
+func (e *Editor) File(name string) *ast.File {
+	for _, f := range e.files {
+		if filename := e.filename(f); filename == name || filepath.Base(filename) == name {
+			return f
+		}
+	}
+	return nil
+}
+
+// Edit replaces the source between pos and end with text, pos and end must be
+// within the same file. Edits may not overlap, but any number of insertions
+// where pos equals end may be made at the same position and are applied in
+// the order they were made.
+func (e *Editor) Edit(pos, end token.Pos, text string) error {
+	if !pos.IsValid() || end < pos {
+		return fmt.Errorf("edit: invalid range %v to %v", pos, end)
+	}
+	file := e.Fset.File(pos)
+	if file == nil || e.Fset.File(end) != file {
+		return fmt.Errorf("edit: range %v to %v is not within a single file", pos, end)
+	}
+	if _, ok := e.src[file.Name()]; !ok {
+		return fmt.Errorf("edit: %v is not a file of package %v", file.Name(), e.Package.Name)
+	}
+	e.edits[file.Name()] = append(e.edits[file.Name()], edit{
+		pos: file.Offset(pos), end: file.Offset(end), text: text})
+	return nil
+}
+
+// Replace replaces old with the source of new. Comments within old are
+// removed while those of new are not printed, use ReplaceSource to replace a
+// node with commented source.
+func (e *Editor) Replace(old, new ast.Node) error {
+	var buf bytes.Buffer
+	if err := format.Node(&buf, e.Fset, new); err != nil {
+		return err
+	}
+	return e.Edit(old.Pos(), old.End(), buf.String())
+}
+
+// ReplaceSource replaces node with src.
+func (e *Editor) ReplaceSource(node ast.Node, src string) error {
+	return e.Edit(node.Pos(), node.End(), src)
+}
+
+// Delete removes node along with the doc comment of declarations and specs.
+// When node is the only content of its lines the lines are removed.
+func (e *Editor) Delete(node ast.Node) error {
+	pos, end := node.Pos(), node.End()
+	var doc *ast.CommentGroup
+	switch node := node.(type) {
+	case *ast.FuncDecl:
+		doc = node.Doc
+	case *ast.GenDecl:
+		doc = node.Doc
+	case *ast.ValueSpec:
+		doc = node.Doc
+	case *ast.TypeSpec:
+		doc = node.Doc
+	case *ast.ImportSpec:
+		doc = node.Doc
+	case *ast.Field:
+		doc = node.Doc
+	}
+	if doc != nil {
+		pos = doc.Pos()
+	}
+
+	if file := e.Fset.File(pos); file != nil {
+		if src, ok := e.src[file.Name()]; ok {
+			start, stop := file.Offset(pos), file.Offset(end)
+			for start > 0 && (src[start-1] == ' ' || src[start-1] == '\t') {
+				start--
+			}
+			for stop < len(src) && (src[stop] == ' ' || src[stop] == '\t') {
+				stop++
+			}
+			if (start == 0 || src[start-1] == '\n') && (stop == len(src) || src[stop] == '\n') {
+				if stop < len(src) {
+					stop++
+				}
+				pos, end = file.Pos(start), file.Pos(stop)
+			}
+		}
+	}
+	return e.Edit(pos, end, ``)
+}
+
+// InsertDecl inserts the declarations within src after the line containing
+// the end of the declaration after, or at the end of file when after is nil.
+func (e *Editor) InsertDecl(file *ast.File, after ast.Decl, src string) error {
+	if _, err := parser.ParseFile(token.NewFileSet(), ``, "package p\n"+src, 0); err != nil {
+		return fmt.Errorf("insert: %v", err)
+	}
+	name := e.filename(file)
+	tf := e.Fset.File(file.Package)
+	if after == nil {
+		return e.Edit(tf.Pos(len(e.src[name])), tf.Pos(len(e.src[name])),
+			"\n"+strings.TrimSpace(src)+"\n")
+	}
+	offset := tf.Offset(after.End())
+	if i := bytes.IndexByte(e.src[name][offset:], '\n'); i >= 0 {
+		offset += i
+	} else {
+		offset = len(e.src[name])
+	}
+	return e.Edit(tf.Pos(offset), tf.Pos(offset), "\n\n"+strings.TrimSpace(src))
+}
+
+// AddImport adds an import of importPath to file, name may be empty. Nothing
+// is done if file already imports importPath by the same name.
+func (e *Editor) AddImport(file *ast.File, name, importPath string) error {
+	for _, spec := range file.Imports {
+		if path, _ := strconv.Unquote(spec.Path.Value); path == importPath {
+			if (spec.Name == nil && len(name) == 0) || (spec.Name != nil && spec.Name.Name == name) {
+				return nil
+			}
+		}
+	}
+	spec := strconv.Quote(importPath)
+	if len(name) > 0 {
+		spec = name + " " + spec
+	}
+
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		if gen.Lparen.IsValid() {
+			return e.Edit(gen.Lparen+1, gen.Lparen+1, "\n\t"+spec)
+		}
+		return e.Edit(gen.End(), gen.End(), "\nimport "+spec)
+	}
+	return e.Edit(file.Name.End(), file.Name.End(), "\n\nimport "+spec+"\n")
+}
+
+// DeleteImport removes each import of importPath from file, the import
+// declaration is removed when it has no other imports.
+func (e *Editor) DeleteImport(file *ast.File, importPath string) error {
+	var found bool
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		var keep int
+		var del []ast.Node
+		for _, spec := range gen.Specs {
+			if path, _ := strconv.Unquote(spec.(*ast.ImportSpec).Path.Value); path == importPath {
+				del = append(del, spec)
+			} else {
+				keep++
+			}
+		}
+		if len(del) > 0 && keep == 0 {
+			del = []ast.Node{gen}
+		}
+		for _, node := range del {
+			found = true
+			if err := e.Delete(node); err != nil {
+				return err
+			}
+		}
+	}
+	if !found {
+		return fmt.Errorf("delete import: %v is not imported by %v", importPath, e.filename(file))
+	}
+	return nil
+}
+
+// Source returns the gofmt'd source of the file with the given path or base
+// name after applying its edits, or its original source when it has none.
+func (e *Editor) Source(name string) ([]byte, error) {
+	f := e.File(name)
+	if f == nil {
+		return nil, fmt.Errorf("source: %v is not a file of package %v", name, e.Package.Name)
+	}
+	name = e.filename(f)
+	edits := e.edits[name]
+	if len(edits) == 0 {
+		return e.src[name], nil
+	}
+	sorted := append([]edit(nil), edits...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].pos < sorted[j].pos
+	})
+
+	var buf bytes.Buffer
+	src, last := e.src[name], 0
+	for _, ed := range sorted {
+		if ed.pos < last {
+			return nil, fmt.Errorf("source: overlapping edits at %v",
+				e.Fset.Position(e.Fset.File(f.Package).Pos(ed.pos)))
+		}
+		buf.Write(src[last:ed.pos])
+		buf.WriteString(ed.text)
+		last = ed.end
+	}
+	buf.Write(src[last:])
+
+	out, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("source: edits of %v are not valid Go: %v", name, err)
+	}
+	return out, nil
+}
+
+// Diff returns the changes made by the edits as a unified diff with files
+// named by their base name, which may be applied from the package directory
+// with "patch -p1".
+func (e *Editor) Diff() (string, error) {
+	return e.diff(e.Package.Dir)
+}
+
+// diff is like Diff with files named relative to dir.
+func (e *Editor) diff(dir string) (string, error) {
+	var buf bytes.Buffer
+	for _, f := range e.files {
+		name := e.filename(f)
+		if len(e.edits[name]) == 0 {
+			continue
+		}
+		out, err := e.Source(name)
+		if err != nil {
+			return ``, err
+		}
+		buf.WriteString(unifiedDiff(tagsPath(dir, name), string(e.src[name]), string(out)))
+	}
+	return buf.String(), nil
+}
+
+// Write writes each file changed by the edits unless DryRun is true and
+// returns the changes as described by Diff. No files are written when any of
+// them fail to print.
+func (e *Editor) Write() (string, error) {
+	diff, err := e.Diff()
+	if err != nil || e.DryRun {
+		return diff, err
+	}
+	changes, err := e.changes()
+	if err != nil {
+		return diff, err
+	}
+	return diff, writeChanges(changes)
+}
+
+// changes returns the source of each file changed by the edits keyed by path.
+func (e *Editor) changes() (map[string][]byte, error) {
+	out := make(map[string][]byte)
+	for _, f := range e.files {
+		name := e.filename(f)
+		if len(e.edits[name]) == 0 {
+			continue
+		}
+		src, err := e.Source(name)
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(src, e.src[name]) {
+			out[name] = src
+		}
+	}
+	return out, nil
+}
+
+func writeChanges(changes map[string][]byte) error {
+	for name, src := range changes {
+		fi, err := os.Stat(name)
+		if err != nil {
+			return err
+		}
+		if err = os.WriteFile(name, src, fi.Mode()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Patch is a set of edits to several packages, such as those returned by
+// Rename.
+type Patch struct {
+	// Dir is the deepest directory containing each of the packages, files are
+	// named relative to it within the diff.
+	Dir     string
+	Editors []*Editor
+}
+
+// NewPatch returns a Patch of the edits made by editors.
+func NewPatch(editors ...*Editor) *Patch {
+	p := &Patch{Editors: editors}
+	for i, e := range editors {
+		if i == 0 {
+			p.Dir = e.Package.Dir
+			continue
+		}
+		for !strings.HasPrefix(e.Package.Dir+string(filepath.Separator), p.Dir+string(filepath.Separator)) {
+			dir := filepath.Dir(p.Dir)
+			if dir == p.Dir {
+				break
+			}
+			p.Dir = dir
+		}
+	}
+	return p
+}
+
+// Diff returns the changes made by the edits as a unified diff with files
+// named relative to Dir.
+func (p *Patch) Diff() (string, error) {
+	var buf bytes.Buffer
+	for _, e := range p.Editors {
+		diff, err := e.diff(p.Dir)
+		if err != nil {
+			return ``, err
+		}
+		buf.WriteString(diff)
+	}
+	return buf.String(), nil
+}
+
+// Write writes each file changed by the edits and returns the changes as
+// described by Diff. No files are written when any of them fail to print.
+func (p *Patch) Write() (string, error) {
+	diff, err := p.Diff()
+	if err != nil {
+		return diff, err
+	}
+	changes := make(map[string][]byte)
+	for _, e := range p.Editors {
+		c, err := e.changes()
+		if err != nil {
+			return diff, err
+		}
+		for name, src := range c {
+			changes[name] = src
+		}
+	}
+	return diff, writeChanges(changes)
+}
@@ -0,0 +1,225 @@
+package srcutil
+
+import (
+	"go/ast"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEditor(t *testing.T) {
+	const editor = "github.com/cstockton/go-srcutil/testdata/editor"
+	pkg, err := FromWorkDir().Import(editor)
+	tmust(t, err)
+
+	lookup := func(t *testing.T, e *Editor, name string) ast.Node {
+		for _, decl := range e.File("editor.go").Decls {
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				if decl.Name.Name == name {
+					return decl
+				}
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					if vs, ok := spec.(*ast.ValueSpec); ok && vs.Names[0].Name == name {
+						return decl
+					}
+				}
+			}
+		}
+		t.Fatalf("exp decl %v", name)
+		return nil
+	}
+
+	t.Run("Files", func(t *testing.T) {
+		e, err := pkg.Editor()
+		tmust(t, err)
+		teq(t, 1, len(e.Files()))
+		teq(t, e.Files()[0], e.File(filepath.Join(pkg.Dir, "editor.go")))
+		if e.File("missing.go") != nil {
+			t.Fatal("exp nil file")
+		}
+
+		// no edits returns the original source
+		src, err := e.Source("editor.go")
+		tmust(t, err)
+		orig, err := os.ReadFile(filepath.Join(pkg.Dir, "editor.go"))
+		tmust(t, err)
+		teq(t, string(orig), string(src))
+		diff, err := e.Diff()
+		tmust(t, err)
+		teq(t, "", diff)
+	})
+	t.Run("Edits", func(t *testing.T) {
+		e, err := pkg.Editor()
+		tmust(t, err)
+		f := e.File("editor.go")
+
+		greet := lookup(t, e, "Greet").(*ast.FuncDecl)
+		tmust(t, e.Replace(greet.Name, ast.NewIdent("Hello")))
+		tmust(t, e.ReplaceSource(greet.Type.Params.List[0].Names[0], "who"))
+		call := greet.Body.List[0].(*ast.ReturnStmt).Results[0].(*ast.CallExpr)
+		tmust(t, e.ReplaceSource(call.Args[2], "strings.TrimSpace(who)"))
+		tmust(t, e.Delete(lookup(t, e, "Unused")))
+		tmust(t, e.InsertDecl(f, lookup(t, e, "Greeting").(ast.Decl),
+			"// Farewell is the default farewell.\nconst Farewell = \"bye\""))
+		tmust(t, e.InsertDecl(f, nil, "// Bye says bye.\nfunc Bye() { log.Print(Farewell) }"))
+		tmust(t, e.AddImport(f, "", "log"))
+		tmust(t, e.AddImport(f, "", "fmt"))
+
+		src, err := e.Source("editor.go")
+		tmust(t, err)
+		exp := `// Package editor is used for testing the srcutil Editor.
+package editor
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// Greeting is the default greeting.
+const Greeting = "hello" // not "hi"
+
+// Farewell is the default farewell.
+const Farewell = "bye"
+
+// Greet returns a greeting for name.
+func Hello(who string) string {
+	// keep this comment
+	return fmt.Sprintf("%s %s", Greeting, strings.TrimSpace(who))
+}
+
+// Bye says bye.
+func Bye() { log.Print(Farewell) }
+`
+		teq(t, exp, string(src))
+
+		diff, err := e.Diff()
+		tmust(t, err)
+		if !strings.HasPrefix(diff, "--- a/editor.go\n+++ b/editor.go\n@@ -3,17 +3,21 @@\n") {
+			t.Fatalf("exp unified diff header; got:\n%v", diff)
+		}
+		for _, line := range []string{
+			"-func Greet(name string) string {", "+func Hello(who string) string {",
+			"+\t\"log\"", "-// Unused is not used.", "+func Bye() { log.Print(Farewell) }",
+		} {
+			if !strings.Contains(diff, "\n"+line+"\n") {
+				t.Fatalf("exp diff to contain %q; got:\n%v", line, diff)
+			}
+		}
+	})
+	t.Run("Imports", func(t *testing.T) {
+		e, err := pkg.Editor()
+		tmust(t, err)
+		f := e.File("editor.go")
+		tmust(t, e.DeleteImport(f, "strings"))
+		tmust(t, e.AddImport(f, "str", "strings"))
+		call := lookup(t, e, "Greet").(*ast.FuncDecl).Body.List[0].(*ast.ReturnStmt).
+			Results[0].(*ast.CallExpr).Args[2].(*ast.CallExpr)
+		tmust(t, e.Replace(call.Fun.(*ast.SelectorExpr).X, ast.NewIdent("str")))
+
+		src, err := e.Source("editor.go")
+		tmust(t, err)
+		if !strings.Contains(string(src), "import (\n\t\"fmt\"\n\tstr \"strings\"\n)\n") {
+			t.Fatalf("exp aliased import; got:\n%s", src)
+		}
+		if !strings.Contains(string(src), "str.TrimSpace(name)") {
+			t.Fatalf("exp aliased call; got:\n%s", src)
+		}
+		if err := e.DeleteImport(f, "os"); err == nil {
+			t.Fatal("exp non-nil err")
+		}
+	})
+	t.Run("Failure", func(t *testing.T) {
+		e, err := pkg.Editor()
+		tmust(t, err)
+		f := e.File("editor.go")
+		if err := e.InsertDecl(f, nil, "func {"); err == nil {
+			t.Fatal("exp non-nil err for invalid decl")
+		}
+		greet := lookup(t, e, "Greet")
+		tmust(t, e.ReplaceSource(greet, "func Greet() {}"))
+		tmust(t, e.Delete(greet))
+		if _, err := e.Source("editor.go"); err == nil {
+			t.Fatal("exp non-nil err for overlapping edits")
+		}
+		if _, err := e.Source("missing.go"); err == nil {
+			t.Fatal("exp non-nil err for missing file")
+		}
+		if err := e.Edit(0, 0, ""); err == nil {
+			t.Fatal("exp non-nil err for invalid pos")
+		}
+	})
+	t.Run("Write", func(t *testing.T) {
+		dir := t.TempDir()
+		orig, err := os.ReadFile(filepath.Join(pkg.Dir, "editor.go"))
+		tmust(t, err)
+		path := filepath.Join(dir, "editor.go")
+		tmust(t, os.WriteFile(path, orig, 0644))
+		tmp, err := FromDir(dir).Import(".")
+		tmust(t, err)
+
+		e, err := tmp.Editor()
+		tmust(t, err)
+		e.DryRun = true
+		tmust(t, e.Delete(lookup(t, e, "Unused")))
+		diff, err := e.Write()
+		tmust(t, err)
+		if !strings.Contains(diff, "-func Unused() {}\n") {
+			t.Fatalf("exp diff to remove Unused; got:\n%v", diff)
+		}
+		got, err := os.ReadFile(path)
+		tmust(t, err)
+		teq(t, string(orig), string(got))
+
+		e.DryRun = false
+		_, err = e.Write()
+		tmust(t, err)
+		got, err = os.ReadFile(path)
+		tmust(t, err)
+		if strings.Contains(string(got), "Unused") {
+			t.Fatalf("exp Unused to be removed; got:\n%s", got)
+		}
+	})
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	var a, b []string
+	for i := 1; i <= 20; i++ {
+		a = append(a, strings.Repeat("x", i))
+		b = append(b, strings.Repeat("x", i))
+	}
+	b[1], b[16] = "changed", "changed"
+	b = append(b[:9], b[10:]...)
+	got := unifiedDiff("f.go", strings.Join(a, "\n")+"\n", strings.Join(b, "\n")+"\n")
+	exp := `--- a/f.go
++++ b/f.go
+@@ -1,5 +1,5 @@
+ x
+-xx
++changed
+ xxx
+ xxxx
+ xxxxx
+@@ -7,14 +7,13 @@
+ xxxxxxx
+ xxxxxxxx
+ xxxxxxxxx
+-xxxxxxxxxx
+ xxxxxxxxxxx
+ xxxxxxxxxxxx
+ xxxxxxxxxxxxx
+ xxxxxxxxxxxxxx
+ xxxxxxxxxxxxxxx
+ xxxxxxxxxxxxxxxx
+-xxxxxxxxxxxxxxxxx
++changed
+ xxxxxxxxxxxxxxxxxx
+ xxxxxxxxxxxxxxxxxxx
+ xxxxxxxxxxxxxxxxxxxx
+`
+	teq(t, exp, got)
+	teq(t, "", unifiedDiff("f.go", "a\n", "a\n"))
+}
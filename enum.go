@@ -0,0 +1,204 @@
+package srcutil
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// EnumOptions configures the source generated by GenerateEnum.
+type EnumOptions struct {
+	// TrimPrefix is removed from the name of each constant to form its string
+	// value, I.E. "Color" for the constant ColorRed has the value "Red".
+	TrimPrefix string
+
+	// Descriptions adds a Description method returning the doc comment, or
+	// else the line comment, of each constant.
+	Descriptions bool
+}
+
+// GenerateEnum returns the gofmt'd source of a file within this package which
+// declares methods for the named integer type typeName like the stringer
+// command does. The values are the constants of typeName declared within this
+// package, when several constants have the same value the first declared is
+// used for its string. An error is returned when several constants have the
+// same string once TrimPrefix is removed.
+//
+// For a type T the file declares the String, MarshalText and Description
+// methods on T, the UnmarshalText method on *T, the ParseT func which returns
+// the value for a string and the TValues func which returns each value in the
+// order they are declared.
+func (p *Package) GenerateEnum(typeName string, opts EnumOptions) ([]byte, error) {
+	if err := p.init(); err != nil {
+		return nil, err
+	}
+	obj, ok := p.tc.typesPkg.Scope().Lookup(typeName).(*types.TypeName)
+	if !ok {
+		return nil, fmt.Errorf("enum: type %v was not found", typeName)
+	}
+	basic, ok := obj.Type().Underlying().(*types.Basic)
+	if !ok || basic.Info()&types.IsInteger == 0 {
+		return nil, fmt.Errorf("enum: %v is not a named integer type", typeName)
+	}
+
+	comments := p.constComments()
+	data := enumData{Package: p.Name, Type: typeName, Basic: basic.Name(),
+		Descriptions: opts.Descriptions}
+	seen := make(map[string]bool)
+	strs := make(map[string]string)
+	for _, c := range p.enumConsts(obj) {
+		value, str := c.Val().ExactString(), strings.TrimPrefix(c.Name(), opts.TrimPrefix)
+		if prev, ok := strs[str]; ok {
+			return nil, fmt.Errorf("enum: constants %v and %v both have the string %q",
+				prev, c.Name(), str)
+		}
+		strs[str] = c.Name()
+		data.Consts = append(data.Consts, enumConst{
+			Name: c.Name(), String: str,
+			Description: comments[c.Name()], Duplicate: seen[value]})
+		seen[value] = true
+	}
+	if len(data.Consts) == 0 {
+		return nil, fmt.Errorf("enum: %v has no constants", typeName)
+	}
+
+	var buf bytes.Buffer
+	if err := enumTmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return format.Source(buf.Bytes())
+}
+
+// enumConsts returns the constants of the named type obj in the order they
+// are declared, excluding those declared within tests.
+func (p *Package) enumConsts(obj *types.TypeName) []*types.Const {
+	var out []*types.Const
+	scope := p.tc.typesPkg.Scope()
+	for _, name := range scope.Names() {
+		c, ok := scope.Lookup(name).(*types.Const)
+		if !ok || !types.Identical(c.Type(), obj.Type()) || name == "_" {
+			continue
+		}
+		if isTestFile(p.tc.fileSet.Position(c.Pos())) {
+			continue
+		}
+		out = append(out, c)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := p.tc.fileSet.Position(out[i].Pos()), p.tc.fileSet.Position(out[j].Pos())
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		return a.Offset < b.Offset
+	})
+	return out
+}
+
+// constComments returns the doc comment, or else the line comment, of each
+// package level const spec as a single line keyed by name.
+func (p *Package) constComments() map[string]string {
+	out := make(map[string]string)
+	for _, f := range p.tc.astPkg.Files {
+		for _, decl := range f.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.CONST {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				vs := spec.(*ast.ValueSpec)
+				group := vs.Doc
+				if group == nil {
+					group = vs.Comment
+				}
+				text := strings.Join(strings.Fields(group.Text()), " ")
+				for _, ident := range vs.Names {
+					out[ident.Name] = text
+				}
+			}
+		}
+	}
+	return out
+}
+
+type enumData struct {
+	Package, Type, Basic string
+	Descriptions         bool
+	Consts               []enumConst
+}
+
+type enumConst struct {
+	Name, String, Description string
+
+	// Duplicate is true when an earlier constant has the same value.
+	Duplicate bool
+}
+
+var enumTmpl = template.Must(template.New("enum").Parse(`// Code generated by srcutil. DO NOT EDIT.
+
+package {{.Package}}
+
+import "fmt"
+
+// String implements fmt.Stringer.
+func (x {{.Type}}) String() string {
+	switch x {
+	{{- range .Consts}}{{if not .Duplicate}}
+	case {{.Name}}:
+		return {{printf "%q" .String}}
+	{{- end}}{{end}}
+	}
+	return fmt.Sprintf("{{.Type}}(%d)", {{.Basic}}(x))
+}
+{{if .Descriptions}}
+// Description returns the comment of the constant x.
+func (x {{.Type}}) Description() string {
+	switch x {
+	{{- range .Consts}}{{if not .Duplicate}}
+	case {{.Name}}:
+		return {{printf "%q" .Description}}
+	{{- end}}{{end}}
+	}
+	return ""
+}
+{{end}}
+// Parse{{.Type}} returns the {{.Type}} for s.
+func Parse{{.Type}}(s string) ({{.Type}}, error) {
+	switch s {
+	{{- range .Consts}}
+	case {{printf "%q" .String}}:
+		return {{.Name}}, nil
+	{{- end}}
+	}
+	return 0, fmt.Errorf("{{.Package}}: invalid {{.Type}} %q", s)
+}
+
+// {{.Type}}Values returns each {{.Type}} in the order they are declared.
+func {{.Type}}Values() []{{.Type}} {
+	return []{{.Type}}{
+		{{- range .Consts}}{{if not .Duplicate}}
+		{{.Name}},
+		{{- end}}{{end}}
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (x {{.Type}}) MarshalText() ([]byte, error) {
+	return []byte(x.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (x *{{.Type}}) UnmarshalText(text []byte) error {
+	v, err := Parse{{.Type}}(string(text))
+	if err != nil {
+		return err
+	}
+	*x = v
+	return nil
+}
+`))
@@ -0,0 +1,125 @@
+package srcutil
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"testing"
+)
+
+func TestGenerateEnum(t *testing.T) {
+	const enum = "github.com/cstockton/go-srcutil/testdata/enum"
+	ctx := FromWorkDir()
+	pkg, err := ctx.Import(enum)
+	tmust(t, err)
+
+	src, err := pkg.GenerateEnum("Color", EnumOptions{TrimPrefix: "Color", Descriptions: true})
+	tmust(t, err)
+	exp := `// Code generated by srcutil. DO NOT EDIT.
+
+package enum
+
+import "fmt"
+
+// String implements fmt.Stringer.
+func (x Color) String() string {
+	switch x {
+	case ColorRed:
+		return "Red"
+	case ColorGreen:
+		return "Green"
+	case ColorBlue:
+		return "Blue"
+	}
+	return fmt.Sprintf("Color(%d)", uint8(x))
+}
+
+// Description returns the comment of the constant x.
+func (x Color) Description() string {
+	switch x {
+	case ColorRed:
+		return "ColorRed is the color red."
+	case ColorGreen:
+		return "the color green"
+	case ColorBlue:
+		return ""
+	}
+	return ""
+}
+
+// ParseColor returns the Color for s.
+func ParseColor(s string) (Color, error) {
+	switch s {
+	case "Red":
+		return ColorRed, nil
+	case "Green":
+		return ColorGreen, nil
+	case "Blue":
+		return ColorBlue, nil
+	case "Default":
+		return ColorDefault, nil
+	}
+	return 0, fmt.Errorf("enum: invalid Color %q", s)
+}
+
+// ColorValues returns each Color in the order they are declared.
+func ColorValues() []Color {
+	return []Color{
+		ColorRed,
+		ColorGreen,
+		ColorBlue,
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (x Color) MarshalText() ([]byte, error) {
+	return []byte(x.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (x *Color) UnmarshalText(text []byte) error {
+	v, err := ParseColor(string(text))
+	if err != nil {
+		return err
+	}
+	*x = v
+	return nil
+}
+`
+	teq(t, exp, string(src))
+
+	t.Run("TypeCheck", func(t *testing.T) {
+		src, err := pkg.GenerateEnum("Color", EnumOptions{})
+		tmust(t, err)
+		fset := token.NewFileSet()
+		f, err := parser.ParseFile(fset, "enum_string.go", src, 0)
+		tmust(t, err)
+		files := []*ast.File{f}
+		pkgFiles := pkg.Files()
+		for _, path := range pkgFiles.SourcePaths() {
+			f, err := parser.ParseFile(fset, path, nil, 0)
+			tmust(t, err)
+			files = append(files, f)
+		}
+		conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+		_, err = conf.Check("enum", fset, files, nil)
+		tmust(t, err)
+	})
+	t.Run("Failure", func(t *testing.T) {
+		for _, name := range []string{"Plain", "Missing", "Empty"} {
+			if _, err := pkg.GenerateEnum(name, EnumOptions{}); err == nil {
+				t.Errorf("exp error for %v", name)
+			}
+		}
+
+		_, err := pkg.GenerateEnum("Shade", EnumOptions{TrimPrefix: "Shade"})
+		if err == nil {
+			t.Fatal("exp error for duplicate strings")
+		}
+		teq(t, `enum: constants ShadeDark and Dark both have the string "Dark"`, err.Error())
+		_, err = pkg.GenerateEnum("Shade", EnumOptions{})
+		tmust(t, err)
+	})
+}
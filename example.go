@@ -0,0 +1,234 @@
+package srcutil
+
+import (
+	"bytes"
+	"fmt"
+	"go/doc"
+	"go/format"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Example is a doc.Example along with the identifier it documents. The
+// Suffix of the embedded doc.Example is set to the label following the
+// identifier, I.E. "second" for "ExampleReader_Read_second".
+type Example struct {
+	doc.Example
+
+	// Target is the identifier the example documents as named by the testing
+	// package, "T.M" for methods and empty for the package example.
+	Target string
+}
+
+// ExamplesOf returns the examples documenting the given target, I.E. "Reader"
+// or "Reader.Read", or the package examples when target is empty. Examples
+// within the package and its external test package are both included.
+func (d *Docs) ExamplesOf(target string) []Example {
+	return d.Package.exampleIndex()[target]
+}
+
+// MissingExamples returns a diagnostic for each exported func, type and
+// method of this package which has no examples.
+func (d *Docs) MissingExamples() []Diagnostic {
+	examples := d.Package.exampleIndex()
+	var out []Diagnostic
+	for _, decl := range d.decls() {
+		switch {
+		case decl.Kind != "func" && decl.Kind != "type" && decl.Kind != "method":
+			continue
+		case decl.Group:
+			continue // interface methods
+		case len(examples[decl.Name]) > 0:
+			continue
+		}
+		out = append(out, Diagnostic{Pos: decl.Pos, Category: "example",
+			Message: fmt.Sprintf("exported %s %s has no examples", decl.Kind, decl.Name)})
+	}
+	sortDiagnostics(out)
+	return out
+}
+
+// exampleIndex returns the examples of this package keyed by their target.
+func (p *Package) exampleIndex() map[string][]Example {
+	files := p.astFiles(p.tc.astPkg)
+	if p.tc.xtestPkg != nil {
+		files = append(files, p.astFiles(p.tc.xtestPkg)...)
+	}
+	s := doc.Examples(files...)
+	sort.Slice(s, func(i, j int) bool { return s[i].Name < s[j].Name })
+
+	out := make(map[string][]Example)
+	for _, ex := range s {
+		target, suffix := exampleTarget(ex.Name)
+		e := Example{Example: *ex, Target: target}
+		e.Suffix = suffix
+		out[target] = append(out[target], e)
+	}
+	return out
+}
+
+// ExampleProgram is an example materialized as a standalone main package, like
+// the programs go/doc creates for the Go playground.
+type ExampleProgram struct {
+	ImportPath string
+
+	// Name is the name of the example without the "Example" prefix, it is
+	// empty for the package example.
+	Name string
+	Pos  token.Position
+
+	// Source is the gofmt'd source of the program.
+	Source string
+
+	// Output is the expected output as written in the "// Output:" comment,
+	// Unordered is true for "// Unordered output:" comments. EmptyOutput is true
+	// when the output comment is present but has no lines.
+	Output      string
+	Unordered   bool
+	EmptyOutput bool
+}
+
+// HasOutput returns true if the example has an output comment, examples
+// without one are compiled but never run like the go test command does.
+func (e ExampleProgram) HasOutput() bool {
+	return len(e.Output) > 0 || e.EmptyOutput
+}
+
+// String implements fmt.Stringer.
+func (e ExampleProgram) String() string {
+	return "Example" + e.Name
+}
+
+// ExamplePrograms returns a program for each example declared within the
+// external test package of this package, I.E. "package foo_test". Examples
+// declared within the package itself may refer to its unexported identifiers
+// so they can not be materialized and are not included.
+func (d *Docs) ExamplePrograms() []ExampleProgram {
+	p := d.Package
+	if p.tc.xtestPkg == nil {
+		return nil
+	}
+
+	var out []ExampleProgram
+	for _, ex := range doc.Examples(p.astFiles(p.tc.xtestPkg)...) {
+		if ex.Play == nil {
+			continue
+		}
+		var buf bytes.Buffer
+		if err := format.Node(&buf, p.tc.fileSet, ex.Play); err != nil {
+			continue
+		}
+		out = append(out, ExampleProgram{
+			ImportPath:  p.ImportPath,
+			Name:        ex.Name,
+			Pos:         p.tc.fileSet.Position(ex.Code.Pos()),
+			Source:      buf.String(),
+			Output:      ex.Output,
+			Unordered:   ex.Unordered,
+			EmptyOutput: ex.EmptyOutput,
+		})
+	}
+	return out
+}
+
+// VerifyExamples runs each of the ExamplePrograms from the directory of this
+// package and compares their output to the expected output.
+func (d *Docs) VerifyExamples() []ExampleResult {
+	var out []ExampleResult
+	for _, ex := range d.ExamplePrograms() {
+		out = append(out, ex.Run(d.Package.Dir))
+	}
+	return out
+}
+
+// ExampleResult is the result of running an ExampleProgram.
+type ExampleResult struct {
+	Example ExampleProgram
+
+	// Output is what the program wrote to stdout.
+	Output string
+
+	// Diff is empty when the output matched, otherwise each line of the
+	// expected output missing from Output is prefixed with "-" and each
+	// unexpected line with "+".
+	Diff string
+
+	// Err is non-nil when the program failed to compile or exited with a
+	// non-zero status, it contains the output of the go command.
+	Err error
+}
+
+// Passed returns true if the example compiled, ran and had the expected
+// output.
+func (r ExampleResult) Passed() bool {
+	return r.Err == nil && len(r.Diff) == 0
+}
+
+// String implements fmt.Stringer.
+func (r ExampleResult) String() string {
+	switch {
+	case r.Err != nil:
+		return fmt.Sprintf("FAIL %v: %v", r.Example, r.Err)
+	case len(r.Diff) > 0:
+		return fmt.Sprintf("FAIL %v: output mismatch:\n%s", r.Example, r.Diff)
+	}
+	return fmt.Sprintf("ok   %v", r.Example)
+}
+
+// Run compiles and runs the program with the go command from dir, which must
+// be within the module or GOPATH of the example so its imports are resolved.
+// Programs without an output comment are only compiled.
+func (e ExampleProgram) Run(dir string) ExampleResult {
+	res := ExampleResult{Example: e}
+	tmp, err := os.MkdirTemp(``, "srcutil-example")
+	if err != nil {
+		res.Err = err
+		return res
+	}
+	defer os.RemoveAll(tmp)
+
+	path := filepath.Join(tmp, "main.go")
+	if err = os.WriteFile(path, []byte(e.Source), 0644); err != nil {
+		res.Err = err
+		return res
+	}
+	args := []string{"run", path}
+	if !e.HasOutput() {
+		args = []string{"build", "-o", filepath.Join(tmp, "example"), path}
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.Command("go", args...)
+	cmd.Dir, cmd.Stdout, cmd.Stderr = dir, &stdout, &stderr
+	if err = cmd.Run(); err != nil {
+		res.Err = fmt.Errorf("%v: %s", err, strings.TrimSpace(stderr.String()))
+		return res
+	}
+	if e.HasOutput() {
+		res.Output = stdout.String()
+		res.Diff = outputDiff(e.Output, res.Output, e.Unordered)
+	}
+	return res
+}
+
+// outputDiff compares output the same way the testing package does, ignoring
+// leading and trailing space and optionally the order of lines.
+func outputDiff(want, got string, unordered bool) string {
+	lines := func(s string) string {
+		s = strings.TrimSpace(strings.ReplaceAll(s, "\r\n", "\n"))
+		if unordered {
+			l := strings.Split(s, "\n")
+			sort.Strings(l)
+			s = strings.Join(l, "\n")
+		}
+		if len(s) > 0 {
+			s += "\n"
+		}
+		return s
+	}
+	return lineDiff(lines(want), lines(got))
+}
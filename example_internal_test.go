@@ -0,0 +1,113 @@
+package srcutil
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExamplePrograms(t *testing.T) {
+	const examples = "github.com/cstockton/go-srcutil/testdata/examples"
+	ctx := FromWorkDir()
+	pkg, err := ctx.Import(examples)
+	tmust(t, err)
+	docs := pkg.Docs()
+
+	progs := docs.ExamplePrograms()
+	var names []string
+	for _, prog := range progs {
+		names = append(names, prog.String())
+	}
+	teq(t, []string{"ExampleGreet", "ExampleGreet_broken", "ExampleGreet_mismatch",
+		"ExampleGreet_noOutput", "ExampleGreet_unordered"}, names)
+
+	t.Run("Program", func(t *testing.T) {
+		prog := progs[0]
+		teq(t, examples, prog.ImportPath)
+		teq(t, 9, prog.Pos.Line)
+		teq(t, "Hello, gopher\n", prog.Output)
+		teq(t, true, prog.HasOutput())
+		teq(t, false, progs[3].HasOutput())
+		teq(t, true, progs[4].Unordered)
+		teq(t, true, strings.HasPrefix(prog.Source, "package main\n"))
+		teq(t, true, strings.Contains(prog.Source, "func main() {\n"))
+		teq(t, true, strings.Contains(prog.Source, `"`+examples+`"`))
+	})
+	t.Run("Verify", func(t *testing.T) {
+		if testing.Short() {
+			t.Skip("skipping example runs in short mode")
+		}
+		results := docs.VerifyExamples()
+		teq(t, len(progs), len(results))
+		teq(t, true, results[0].Passed())
+		teq(t, "Hello, gopher\n", results[0].Output)
+		teq(t, "ok   ExampleGreet", results[0].String())
+
+		teq(t, false, results[1].Passed())
+		teq(t, true, results[1].Err != nil)
+		teq(t, true, strings.HasPrefix(results[1].String(), "FAIL ExampleGreet_broken: "))
+
+		teq(t, false, results[2].Passed())
+		teq(t, "-Hello, world\n+Hello, gopher\n same\n", results[2].Diff)
+
+		teq(t, true, results[3].Passed())
+		teq(t, ``, results[3].Output)
+		teq(t, true, results[4].Passed())
+	})
+}
+
+func TestLineDiff(t *testing.T) {
+	teq(t, ``, lineDiff("a\nb\n", "a\nb\n"))
+	teq(t, " a\n-b\n+c\n d\n", lineDiff("a\nb\nd\n", "a\nc\nd\n"))
+	teq(t, "+a\n", lineDiff(``, "a\n"))
+	teq(t, "-a\n", lineDiff("a", ``))
+	teq(t, ``, outputDiff("b\na\n", " a\nb", true))
+	teq(t, "-b\n a\n+b\n", outputDiff("b\na", "a\nb", false))
+}
+
+func TestExampleTargets(t *testing.T) {
+	const examples = "github.com/cstockton/go-srcutil/testdata/examples"
+	ctx := FromWorkDir()
+	pkg, err := ctx.Import(examples)
+	tmust(t, err)
+	docs := pkg.Docs()
+
+	labels := func(s []Example) (out []string) {
+		for _, ex := range s {
+			out = append(out, ex.Target+"/"+ex.Suffix)
+		}
+		return
+	}
+	t.Run("ExamplesOf", func(t *testing.T) {
+		teq(t, []string{"/internal"}, labels(docs.ExamplesOf(``)))
+		teq(t, []string{"Greet/", "Greet/broken", "Greet/mismatch", "Greet/noOutput",
+			"Greet/unordered"}, labels(docs.ExamplesOf("Greet")))
+		teq(t, []string{"Counter.Inc/"}, labels(docs.ExamplesOf("Counter.Inc")))
+		teq(t, 0, len(docs.ExamplesOf("Counter")))
+	})
+	t.Run("Wrappers", func(t *testing.T) {
+		funcs := pkg.Funcs()
+		teq(t, 1, len(funcs))
+		teq(t, 5, len(funcs[0].Examples))
+		teq(t, "Hello, gopher\n", funcs[0].Examples[0].Output)
+
+		structs := pkg.Structs()
+		teq(t, 0, len(structs[0].Examples))
+
+		ms, err := pkg.MethodSet("Counter")
+		tmust(t, err)
+		teq(t, 0, len(ms.Examples))
+		teq(t, []string{"Counter.Inc/"}, labels(ms.Methods["Inc"].Examples))
+		teq(t, 0, len(ms.Methods["Reset"].Examples))
+	})
+	t.Run("MissingExamples", func(t *testing.T) {
+		var got []string
+		for _, d := range docs.MissingExamples() {
+			teq(t, "example", d.Category)
+			got = append(got, d.Message)
+		}
+		teq(t, []string{
+			"exported type Counter has no examples",
+			"exported method Counter.Reset has no examples",
+		}, got)
+	})
+}
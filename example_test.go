@@ -23,12 +23,16 @@ func Example() {
 
 	// Output:
 	// // io: Package io provides basic interfaces to I/O primitives.
+	// var Discard io.Writer
 	// var EOF error
 	// var ErrClosedPipe error
+	// var ErrInvalidWrite error
 	// var ErrNoProgress error
+	// var ErrOffset error
 	// var ErrShortBuffer error
 	// var ErrShortWrite error
 	// var ErrUnexpectedEOF error
+	// var ErrWhence error
 }
 
 func ExamplePackage_Docs() {
@@ -69,24 +73,26 @@ func ExamplePackage_Docs() {
 	//
 	// // Seek whence values.var EOF
 	// // Seek whence values.var ErrClosedPipe
+	// // Seek whence values.var ErrInvalidWrite
 	// // Seek whence values.var ErrNoProgress
+	// // Seek whence values.var ErrOffset
 	// // Seek whence values.var ErrShortBuffer
 	// // Seek whence values.var ErrShortWrite
 	// // Seek whence values.var ErrUnexpectedEOF
+	// // Seek whence values.var ErrWhence
 	//
 	// // ByteReader is the interface that wraps the ReadByte method.
 	// // A LimitedReader reads from R but limits the amount of data returned to just N bytes.
 	// // A PipeReader is the read half of a pipe.
-	// // Pipe creates a synchronous in-memory pipe.
 	// // Reader is the interface that wraps the basic Read method.
 	// // LimitReader returns a Reader that reads from r but stops with EOF after n bytes.
 	// // MultiReader returns a Reader that's the logical concatenation of the provided input readers.
-	// // TeeReader returns a Reader that writes to w what it reads from r.
+	// // TeeReader returns a [Reader] that writes to w what it reads from r.
 	// // ReaderAt is the interface that wraps the basic ReadAt method.
 	// // ReaderFrom is the interface that wraps the ReadFrom method.
 	// // RuneReader is the interface that wraps the ReadRune method.
-	// // SectionReader implements Read, Seek, and ReadAt on a section of an underlying ReaderAt.
-	// // NewSectionReader returns a SectionReader that reads from r starting at offset off and stops with EOF after n bytes.
+	// // SectionReader implements Read, Seek, and ReadAt on a section of an underlying [ReaderAt].
+	// // NewSectionReader returns a [SectionReader] that reads from r starting at offset off and stops with EOF after n bytes.
 }
 
 func ExamplePackage_Methods() {
@@ -106,7 +112,7 @@ func ExamplePackage_Methods() {
 	printer(pkgMethods["Reader"])
 
 	// Output:
-	// type Reader (18 methods)
+	// type Reader (20 methods)
 	//   Buffered()
 	//     returns (int)
 	//   Discard(n int)
@@ -129,12 +135,16 @@ func ExamplePackage_Methods() {
 	//     returns (string, error)
 	//   Reset(r io.Reader)
 	//     returns ()
+	//   Size()
+	//     returns (int)
 	//   UnreadByte()
 	//     returns (error)
 	//   UnreadRune()
 	//     returns (error)
 	//   WriteTo(w io.Writer)
 	//     returns (n int64, err error)
+	//   collectFragments(delim byte)
+	//     returns (fullBuffers [][]byte, finalFragment []byte, totalLen int, err error)
 	//   fill()
 	//     returns ()
 	//   readErr()
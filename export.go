@@ -0,0 +1,288 @@
+package srcutil
+
+import (
+	"encoding/json"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"sort"
+)
+
+// ExportVersion is the version of the schema described by the Export types.
+// It is incremented each time a field is removed or changes meaning, while new
+// fields may be added within the same version.
+const ExportVersion = 1
+
+// Export is the machine readable model of a package, it is the value encoded
+// by Package.MarshalJSON. Only exported identifiers are included and each of
+// the slices are sorted by name, slices with no elements are omitted.
+type Export struct {
+	// Version is always ExportVersion.
+	Version    int    `json:"version"`
+	ImportPath string `json:"importPath"`
+	Name       string `json:"name"`
+	Dir        string `json:"dir"`
+
+	// Doc is the package comment.
+	Doc string `json:"doc,omitempty"`
+
+	// Files and TestFiles are file names relative to Dir.
+	Files     []string `json:"files,omitempty"`
+	TestFiles []string `json:"testFiles,omitempty"`
+
+	// Imports are the import paths imported by Files.
+	Imports []string      `json:"imports,omitempty"`
+	Consts  []ExportValue `json:"consts,omitempty"`
+	Vars    []ExportValue `json:"vars,omitempty"`
+	Funcs   []ExportFunc  `json:"funcs,omitempty"`
+	Types   []ExportType  `json:"types,omitempty"`
+	Notes   []ExportNote  `json:"notes,omitempty"`
+}
+
+// ExportPos is a position within a file, File is relative to Export.Dir.
+type ExportPos struct {
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+}
+
+// ExportValue is a const, var or struct field. All types within the model are
+// written as Go source, qualified by import path for other packages.
+type ExportValue struct {
+	Name string    `json:"name"`
+	Type string    `json:"type"`
+	Doc  string    `json:"doc,omitempty"`
+	Pos  ExportPos `json:"pos"`
+
+	// Value is the exact value of a const.
+	Value string `json:"value,omitempty"`
+
+	// Tag and Embedded are only set for struct fields.
+	Tag      string `json:"tag,omitempty"`
+	Embedded bool   `json:"embedded,omitempty"`
+}
+
+// ExportParam is a single type parameter, parameter or result. The Name of a
+// parameter or result may be empty.
+type ExportParam struct {
+	Name string `json:"name,omitempty"`
+	Type string `json:"type"`
+}
+
+// ExportFunc is a func, method or interface method.
+type ExportFunc struct {
+	Name string    `json:"name"`
+	Doc  string    `json:"doc,omitempty"`
+	Pos  ExportPos `json:"pos"`
+
+	// Signature is the full signature such as "func(r io.Reader) error".
+	Signature string `json:"signature"`
+
+	// Recv is the receiver type such as "T" or "*T", it is empty for funcs and
+	// interface methods.
+	Recv       string        `json:"recv,omitempty"`
+	TypeParams []ExportParam `json:"typeParams,omitempty"`
+	Params     []ExportParam `json:"params,omitempty"`
+	Results    []ExportParam `json:"results,omitempty"`
+	Variadic   bool          `json:"variadic,omitempty"`
+}
+
+// ExportType is a named type declaration.
+type ExportType struct {
+	Name string    `json:"name"`
+	Doc  string    `json:"doc,omitempty"`
+	Pos  ExportPos `json:"pos"`
+
+	// Kind is one of "struct", "interface", "alias" or "defined".
+	Kind       string        `json:"kind"`
+	TypeParams []ExportParam `json:"typeParams,omitempty"`
+
+	// Underlying is the type definition for the "alias" and "defined" kinds.
+	Underlying string        `json:"underlying,omitempty"`
+	Fields     []ExportValue `json:"fields,omitempty"`
+
+	// Methods are the methods declared for the type, or the complete method
+	// set for interfaces.
+	Methods []ExportFunc `json:"methods,omitempty"`
+}
+
+// ExportNote is a marked comment as returned by Docs.Notes.
+type ExportNote struct {
+	Marker string    `json:"marker"`
+	UID    string    `json:"uid"`
+	Body   string    `json:"body"`
+	Pos    ExportPos `json:"pos"`
+}
+
+// MarshalJSON implements json.Marshaler by encoding the value of Export.
+func (p *Package) MarshalJSON() ([]byte, error) {
+	ex, err := p.Export()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(ex)
+}
+
+// Export returns the Export model of this package. An error is returned when
+// the package could not be type checked.
+func (p *Package) Export() (Export, error) {
+	if err := p.init(); err != nil {
+		return Export{}, err
+	}
+	files := p.Files()
+	ex := Export{
+		Version:    ExportVersion,
+		ImportPath: p.ImportPath,
+		Name:       p.Name,
+		Dir:        p.Dir,
+		Doc:        p.tc.docPkg.Doc,
+	}
+	ex.TestFiles = append(ex.TestFiles, p.TestGoFiles...)
+	ex.TestFiles = append(ex.TestFiles, p.XTestGoFiles...)
+	sort.Strings(ex.TestFiles)
+	ex.Imports = append(ex.Imports, p.Imports...)
+	for _, path := range files.SourcePaths() {
+		ex.Files = append(ex.Files, filepath.Base(path))
+	}
+
+	e := exporter{pkg: p, qual: types.RelativeTo(p.tc.typesPkg), docs: p.docIndex()}
+	scope := p.tc.typesPkg.Scope()
+	for _, name := range scope.Names() {
+		obj := scope.Lookup(name)
+		if !obj.Exported() || isTest(name, "Test") || isTest(name, "Example") {
+			continue
+		}
+		if isTestFile(p.tc.fileSet.Position(obj.Pos())) {
+			continue
+		}
+		switch obj := obj.(type) {
+		case *types.Const:
+			v := e.value(obj, name)
+			v.Value = obj.Val().ExactString()
+			ex.Consts = append(ex.Consts, v)
+		case *types.Var:
+			ex.Vars = append(ex.Vars, e.value(obj, name))
+		case *types.Func:
+			ex.Funcs = append(ex.Funcs, e.fn(obj, name))
+		case *types.TypeName:
+			ex.Types = append(ex.Types, e.typ(obj))
+		}
+	}
+
+	for marker, notes := range p.tc.docPkg.Notes {
+		for _, note := range notes {
+			ex.Notes = append(ex.Notes, ExportNote{
+				Marker: marker, UID: note.UID, Body: note.Body,
+				Pos: e.pos(p.tc.docFileSet.Position(note.Pos))})
+		}
+	}
+	sort.SliceStable(ex.Notes, func(i, j int) bool {
+		return ex.Notes[i].Marker < ex.Notes[j].Marker
+	})
+	return ex, nil
+}
+
+type exporter struct {
+	pkg  *Package
+	qual types.Qualifier
+	docs map[string]string
+}
+
+func (e *exporter) position(obj types.Object) ExportPos {
+	if obj.Pkg() != e.pkg.tc.typesPkg {
+		return ExportPos{} // declared in another package, I.E. embedded methods
+	}
+	return e.pos(e.pkg.tc.fileSet.Position(obj.Pos()))
+}
+
+func (e *exporter) pos(position token.Position) ExportPos {
+	return ExportPos{
+		File:   filepath.Base(position.Filename),
+		Line:   position.Line,
+		Column: position.Column,
+	}
+}
+
+func (e *exporter) value(obj types.Object, key string) ExportValue {
+	return ExportValue{
+		Name: obj.Name(),
+		Type: types.TypeString(obj.Type(), e.qual),
+		Doc:  e.docs[key],
+		Pos:  e.position(obj),
+	}
+}
+
+func (e *exporter) params(tuple *types.Tuple) (out []ExportParam) {
+	for i := 0; i < tuple.Len(); i++ {
+		v := tuple.At(i)
+		out = append(out, ExportParam{
+			Name: v.Name(), Type: types.TypeString(v.Type(), e.qual)})
+	}
+	return
+}
+
+func (e *exporter) typeParams(tparams *types.TypeParamList) (out []ExportParam) {
+	for i := 0; i < tparams.Len(); i++ {
+		tparam := tparams.At(i)
+		out = append(out, ExportParam{
+			Name: tparam.Obj().Name(),
+			Type: types.TypeString(tparam.Constraint(), e.qual)})
+	}
+	return
+}
+
+func (e *exporter) fn(obj *types.Func, key string) ExportFunc {
+	sig := obj.Type().(*types.Signature)
+	f := ExportFunc{
+		Name:       obj.Name(),
+		Doc:        e.docs[key],
+		Pos:        e.position(obj),
+		Signature:  types.TypeString(sig, e.qual),
+		TypeParams: e.typeParams(sig.TypeParams()),
+		Params:     e.params(sig.Params()),
+		Results:    e.params(sig.Results()),
+		Variadic:   sig.Variadic(),
+	}
+	if recv := sig.Recv(); recv != nil {
+		if _, ok := recv.Type().Underlying().(*types.Interface); !ok {
+			f.Recv = types.TypeString(recv.Type(), e.qual)
+		}
+	}
+	return f
+}
+
+func (e *exporter) typ(obj *types.TypeName) ExportType {
+	d := newTypeDecl(obj, e.qual)
+	typ := ExportType{
+		Name:       obj.Name(),
+		Doc:        e.docs[obj.Name()],
+		Pos:        e.position(obj),
+		Kind:       d.kind,
+		Underlying: d.underlying,
+	}
+	if d.named == nil {
+		return typ
+	}
+	typ.TypeParams = e.typeParams(d.named.TypeParams())
+	for i, field := range d.fields {
+		v := e.value(field, obj.Name()+"."+field.Name())
+		v.Tag, v.Embedded = d.tags[i], field.Embedded()
+		typ.Fields = append(typ.Fields, v)
+	}
+	for _, m := range d.methods {
+		typ.Methods = append(typ.Methods, e.fn(m, obj.Name()+"."+m.Name()))
+	}
+	if d.kind == "interface" {
+		return typ
+	}
+
+	for i := 0; i < d.named.NumMethods(); i++ {
+		if m := d.named.Method(i); m.Exported() {
+			typ.Methods = append(typ.Methods, e.fn(m, obj.Name()+"."+m.Name()))
+		}
+	}
+	sort.Slice(typ.Methods, func(i, j int) bool {
+		return typ.Methods[i].Name < typ.Methods[j].Name
+	})
+	return typ
+}
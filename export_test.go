@@ -0,0 +1,82 @@
+package srcutil
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestExport(t *testing.T) {
+	ctx := FromWorkDir()
+	pkg, err := ctx.Import(tPkg.ImportPath)
+	tmust(t, err)
+	ex, err := pkg.Export()
+	tmust(t, err)
+
+	t.Run("Package", func(t *testing.T) {
+		teq(t, ExportVersion, ex.Version)
+		teq(t, tPkg.ImportPath, ex.ImportPath)
+		teq(t, tPkg.Name, ex.Name)
+		teq(t, tPkg.Path, ex.Dir)
+		teq(t, tPkg.PkgNames, ex.Files)
+		teq(t, tPkg.PkgTests, ex.TestFiles)
+		teq(t, true, len(ex.Doc) > 0)
+		teq(t, 4, len(ex.Notes))
+		teq(t, ExportNote{Marker: "HELLO", UID: "cstockton",
+			Body: "Note hello 1 for testing.\n",
+			Pos:  ExportPos{File: "tpkg_private.go", Line: 66, Column: 1}}, ex.Notes[0])
+	})
+	t.Run("Values", func(t *testing.T) {
+		teq(t, ExportValue{Name: "ConstantOne", Type: "untyped int", Value: "42",
+			Doc: "ConstantOne ipsum non lacus mattis.\n",
+			Pos: ExportPos{File: "tpkg.go", Line: 33, Column: 2}}, ex.Consts[0])
+		teq(t, 3, len(ex.Vars))
+		teq(t, "VariableOne", ex.Vars[0].Name)
+		teq(t, "int", ex.Vars[0].Type)
+	})
+	t.Run("Funcs", func(t *testing.T) {
+		teq(t, 3, len(ex.Funcs))
+		f := ex.Funcs[2]
+		teq(t, "StringFunc", f.Name)
+		teq(t, "func(str string) string", f.Signature)
+		teq(t, []ExportParam{{Name: "str", Type: "string"}}, f.Params)
+		teq(t, []ExportParam{{Type: "string"}}, f.Results)
+		teq(t, true, len(f.Doc) > 0)
+	})
+	t.Run("Types", func(t *testing.T) {
+		teq(t, 2, len(ex.Types))
+		typ := ex.Types[0]
+		teq(t, "PublicStruct", typ.Name)
+		teq(t, "struct", typ.Kind)
+		teq(t, 2, len(typ.Fields))
+		teq(t, `tagOne:"structtag1" tagTwo:"structtag2"`, typ.Fields[0].Tag)
+		teq(t, 6, len(typ.Methods))
+		teq(t, "MethodOne", typ.Methods[0].Name)
+		teq(t, "PublicStruct", typ.Methods[0].Recv)
+		teq(t, "*PublicStruct", typ.Methods[1].Recv)
+		teq(t, "MethodOne rutrum convallis lorem lacus, eu fusce mi sapien vitae.\n",
+			typ.Methods[0].Doc)
+	})
+	t.Run("TypeParams", func(t *testing.T) {
+		pkg, err := ctx.Import("github.com/cstockton/go-srcutil/testdata/api/v1")
+		tmust(t, err)
+		ex, err := pkg.Export()
+		tmust(t, err)
+		for _, typ := range ex.Types {
+			switch typ.Name {
+			case "Generic":
+				teq(t, []ExportParam{{Name: "T", Type: "comparable"}}, typ.TypeParams)
+			case "Iface":
+				teq(t, "interface", typ.Kind)
+				teq(t, "Read", typ.Methods[1].Name)
+				teq(t, ExportPos{}, typ.Methods[1].Pos)
+			}
+		}
+	})
+	t.Run("MarshalJSON", func(t *testing.T) {
+		data, err := json.Marshal(pkg)
+		tmust(t, err)
+		var got Export
+		tmust(t, json.Unmarshal(data, &got))
+		teq(t, ex, got)
+	})
+}
@@ -0,0 +1,360 @@
+package srcutil
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/types"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+)
+
+// GenData is the data a template is executed with by Generate.
+type GenData struct {
+	// Package is the first of the packages given to Generate, types declared
+	// within it are not qualified by the qualify template func.
+	Package  *GenPackage
+	Packages []*GenPackage
+}
+
+// GenPackage is the model of a package given to Generate. The declarations
+// are sorted by name and those within _test.go files are not included, Doc
+// fields are only set for exported identifiers.
+type GenPackage struct {
+	ImportPath string
+	Name       string
+	Doc        string
+	Consts     []GenValue
+	Vars       []GenValue
+	Funcs      []GenFunc
+	Structs    []GenStruct
+	Interfaces []GenInterface
+
+	// Types contains every named type, including the structs and interfaces.
+	Types []GenType
+}
+
+// GenValue is a const or var.
+type GenValue struct {
+	Name     string
+	Doc      string
+	Exported bool
+	Type     types.Type
+
+	// Value is the exact value of a const.
+	Value string
+}
+
+// GenVar is a parameter or result of a GenFunc, Name may be empty.
+type GenVar struct {
+	Name string
+	Type types.Type
+}
+
+// GenFunc is a func or method.
+type GenFunc struct {
+	Name      string
+	Doc       string
+	Exported  bool
+	Signature *types.Signature
+	Params    []GenVar
+	Results   []GenVar
+
+	// Variadic is true when the type of the last of Params is a slice that is
+	// declared as "...T".
+	Variadic bool
+
+	// Recv is the receiver of methods such as "*T", it is empty for funcs and
+	// interface methods.
+	Recv string
+}
+
+// GenType is a named type.
+type GenType struct {
+	Name     string
+	Doc      string
+	Exported bool
+	Type     types.Type
+
+	// Kind is one of "struct", "interface", "alias" or "defined".
+	Kind string
+
+	// Methods are the declared methods of the type, or the complete method set
+	// of an interface.
+	Methods []GenFunc
+}
+
+// GenStruct is a named struct type.
+type GenStruct struct {
+	GenType
+	Fields []GenField
+}
+
+// GenInterface is a named interface type.
+type GenInterface struct {
+	GenType
+}
+
+// GenField is a field of a GenStruct.
+type GenField struct {
+	Name     string
+	Doc      string
+	Exported bool
+	Embedded bool
+	Type     types.Type
+
+	// Tag is the raw tag which may be used as {{.Tag.Get "json"}}, while Tags
+	// contains each of its key value pairs in order.
+	Tag  reflect.StructTag
+	Tags []GenTag
+}
+
+// GenTag is a single key value pair of a struct tag.
+type GenTag struct {
+	Key   string
+	Value string
+
+	// Name is the value before the first comma and Options are the comma
+	// separated values following it, I.E. "id" and ["omitempty"] for the value
+	// "id,omitempty".
+	Name    string
+	Options []string
+}
+
+// Generate executes tmpl with a GenData created from pkgs and returns the
+// gofmt'd result. When the result is not valid Go the unformatted result is
+// returned along with the error. A copy of tmpl is executed with the
+// following funcs, which should be given to Funcs before tmpl is parsed by
+// using GenFuncs.
+//
+//	qualify T      the type T as Go source, types declared outside of the
+//	               first package are qualified and imported as described by
+//	               Imports
+//	zero T         the zero value of the type T as Go source
+//	import PATH    adds PATH to the imports and returns an empty string, the
+//	               name it is referred to by is the last element of PATH
+//	               unless it collides with another import
+//	imports        the import declaration for every import, it may appear
+//	               before the types are qualified
+func Generate(tmpl *template.Template, pkgs ...*Package) ([]byte, error) {
+	if len(pkgs) == 0 {
+		return nil, fmt.Errorf("generate: no packages were given")
+	}
+	data := &GenData{}
+	for _, pkg := range pkgs {
+		if err := pkg.init(); err != nil {
+			return nil, err
+		}
+		data.Packages = append(data.Packages, pkg.genPackage())
+	}
+	data.Package = data.Packages[0]
+
+	im, err := pkgs[0].FileImports()
+	if err != nil {
+		return nil, err
+	}
+	g := &generator{imports: im}
+	t, err := tmpl.Clone()
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err = t.Funcs(g.funcs()).Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	src := bytes.Replace(buf.Bytes(), []byte(genImportsMarker), g.imports.Decl(), 1)
+	out, err := format.Source(src)
+	if err != nil {
+		return src, err
+	}
+	return out, nil
+}
+
+// GenFuncs returns the funcs available to templates executed by Generate, which
+// must be given to the Funcs method of a template before it is parsed. They
+// are replaced during Generate.
+func GenFuncs() template.FuncMap {
+	g := &generator{imports: NewImports(``)}
+	return g.funcs()
+}
+
+const genImportsMarker = "\x00imports\x00"
+
+type generator struct {
+	imports *Imports
+}
+
+func (g *generator) funcs() template.FuncMap {
+	return template.FuncMap{
+		"qualify": g.imports.TypeString,
+		"zero":    g.zero,
+		"import": func(path string) string {
+			g.imports.Add(path, ``)
+			return ``
+		},
+		"imports": func() string { return genImportsMarker },
+	}
+}
+
+func (g *generator) zero(typ types.Type) string {
+	if _, ok := typ.(*types.TypeParam); ok {
+		return "*new(" + g.imports.TypeString(typ) + ")"
+	}
+	switch under := typ.Underlying().(type) {
+	case *types.Basic:
+		switch {
+		case under.Info()&types.IsBoolean != 0:
+			return "false"
+		case under.Info()&types.IsString != 0:
+			return `""`
+		case under.Kind() == types.UnsafePointer || under.Kind() == types.UntypedNil:
+			return "nil"
+		}
+		return "0"
+	case *types.Struct, *types.Array:
+		return g.imports.TypeString(typ) + "{}"
+	case *types.Pointer, *types.Slice, *types.Map, *types.Chan, *types.Signature,
+		*types.Interface:
+		return "nil"
+	}
+	return "*new(" + g.imports.TypeString(typ) + ")"
+}
+
+// genPackage returns the GenPackage model of this package.
+func (p *Package) genPackage() *GenPackage {
+	p.init()
+	gp := &GenPackage{ImportPath: p.ImportPath, Name: p.Name, Doc: p.tc.docPkg.Doc}
+	docs := p.docIndex()
+	scope := p.tc.typesPkg.Scope()
+	for _, name := range scope.Names() {
+		obj := scope.Lookup(name)
+		if isTestFile(p.tc.fileSet.Position(obj.Pos())) {
+			continue
+		}
+		switch obj := obj.(type) {
+		case *types.Const:
+			gp.Consts = append(gp.Consts, GenValue{Name: name, Doc: docs[name],
+				Exported: obj.Exported(), Type: obj.Type(), Value: obj.Val().ExactString()})
+		case *types.Var:
+			gp.Vars = append(gp.Vars, GenValue{Name: name, Doc: docs[name],
+				Exported: obj.Exported(), Type: obj.Type()})
+		case *types.Func:
+			gp.Funcs = append(gp.Funcs, genFunc(obj, docs[name]))
+		case *types.TypeName:
+			typ := genType(obj, docs)
+			gp.Types = append(gp.Types, typ)
+			switch under := obj.Type().Underlying().(type) {
+			case *types.Struct:
+				if typ.Kind == "struct" {
+					gp.Structs = append(gp.Structs, GenStruct{
+						GenType: typ, Fields: genFields(name, under, docs)})
+				}
+			case *types.Interface:
+				if typ.Kind == "interface" {
+					gp.Interfaces = append(gp.Interfaces, GenInterface{GenType: typ})
+				}
+			}
+		}
+	}
+	return gp
+}
+
+func genFunc(obj *types.Func, doc string) GenFunc {
+	sig := obj.Type().(*types.Signature)
+	f := GenFunc{Name: obj.Name(), Doc: doc, Exported: obj.Exported(),
+		Signature: sig, Variadic: sig.Variadic()}
+	for i := 0; i < sig.Params().Len(); i++ {
+		v := sig.Params().At(i)
+		f.Params = append(f.Params, GenVar{Name: v.Name(), Type: v.Type()})
+	}
+	for i := 0; i < sig.Results().Len(); i++ {
+		v := sig.Results().At(i)
+		f.Results = append(f.Results, GenVar{Name: v.Name(), Type: v.Type()})
+	}
+	if recv := sig.Recv(); recv != nil && !types.IsInterface(recv.Type()) {
+		f.Recv = types.TypeString(recv.Type(), func(*types.Package) string { return `` })
+	}
+	return f
+}
+
+func genType(obj *types.TypeName, docs map[string]string) GenType {
+	typ := GenType{Name: obj.Name(), Doc: docs[obj.Name()], Exported: obj.Exported(),
+		Type: obj.Type(), Kind: "defined"}
+	named, ok := obj.Type().(*types.Named)
+	switch {
+	case obj.IsAlias():
+		typ.Kind = "alias"
+		return typ
+	case !ok:
+		return typ
+	}
+
+	if iface, ok := named.Underlying().(*types.Interface); ok {
+		typ.Kind = "interface"
+		for i := 0; i < iface.NumMethods(); i++ {
+			m := iface.Method(i)
+			typ.Methods = append(typ.Methods, genFunc(m, docs[obj.Name()+"."+m.Name()]))
+		}
+		return typ
+	}
+	if _, ok := named.Underlying().(*types.Struct); ok {
+		typ.Kind = "struct"
+	}
+	for i := 0; i < named.NumMethods(); i++ {
+		m := named.Method(i)
+		typ.Methods = append(typ.Methods, genFunc(m, docs[obj.Name()+"."+m.Name()]))
+	}
+	sort.Slice(typ.Methods, func(i, j int) bool {
+		return typ.Methods[i].Name < typ.Methods[j].Name
+	})
+	return typ
+}
+
+func genFields(typeName string, s *types.Struct, docs map[string]string) []GenField {
+	var out []GenField
+	for i := 0; i < s.NumFields(); i++ {
+		v := s.Field(i)
+		out = append(out, GenField{
+			Name: v.Name(), Doc: docs[typeName+"."+v.Name()], Exported: v.Exported(),
+			Embedded: v.Embedded(), Type: v.Type(),
+			Tag: reflect.StructTag(s.Tag(i)), Tags: parseTags(s.Tag(i))})
+	}
+	return out
+}
+
+// parseTags parses a struct tag in the conventional format described by
+// reflect.StructTag, parsing stops at the first malformed pair.
+func parseTags(tag string) []GenTag {
+	var out []GenTag
+	for {
+		tag = strings.TrimLeft(tag, " ")
+		i := strings.Index(tag, `:"`)
+		if i <= 0 || strings.ContainsAny(tag[:i], " \"") {
+			return out
+		}
+		key := tag[:i]
+		rest := tag[i+1:]
+		j := 1
+		for j < len(rest) && rest[j] != '"' {
+			if rest[j] == '\\' {
+				j++
+			}
+			j++
+		}
+		if j >= len(rest) {
+			return out
+		}
+		value, err := strconv.Unquote(rest[:j+1])
+		if err != nil {
+			return out
+		}
+		t := GenTag{Key: key, Value: value}
+		parts := strings.Split(value, ",")
+		t.Name, t.Options = parts[0], parts[1:]
+		out = append(out, t)
+		tag = rest[j+1:]
+	}
+}
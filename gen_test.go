@@ -0,0 +1,209 @@
+package srcutil
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"text/template"
+)
+
+func TestGenerate(t *testing.T) {
+	const gen = "github.com/cstockton/go-srcutil/testdata/gen"
+	ctx := FromWorkDir()
+	pkg, err := ctx.Import(gen)
+	tmust(t, err)
+
+	t.Run("Model", func(t *testing.T) {
+		gp := pkg.genPackage()
+		teq(t, "gen", gp.Name)
+		teq(t, gen, gp.ImportPath)
+		teq(t, 1, len(gp.Consts))
+		teq(t, `"1.0"`, gp.Consts[0].Value)
+		teq(t, 1, len(gp.Funcs))
+		teq(t, "Open", gp.Funcs[0].Name)
+		teq(t, 3, len(gp.Types))
+
+		teq(t, 1, len(gp.Structs))
+		user := gp.Structs[0]
+		teq(t, "User is a user.\n", user.Doc)
+		teq(t, "struct", user.Kind)
+		teq(t, 1, len(user.Methods))
+		teq(t, "*User", user.Methods[0].Recv)
+		teq(t, "greeting", user.Methods[0].Params[0].Name)
+		teq(t, 6, len(user.Fields))
+
+		id := user.Fields[0]
+		teq(t, "ID is the id of the user.\n", id.Doc)
+		teq(t, "user_id,pk", id.Tag.Get("db"))
+		teq(t, []GenTag{
+			{Key: "json", Value: "id", Name: "id", Options: []string{}},
+			{Key: "db", Value: "user_id,pk", Name: "user_id", Options: []string{"pk"}},
+		}, id.Tags)
+		teq(t, true, user.Fields[4].Embedded)
+		teq(t, false, user.Fields[5].Exported)
+
+		teq(t, 1, len(gp.Interfaces))
+		store := gp.Interfaces[0]
+		teq(t, "interface", store.Kind)
+		teq(t, 2, len(store.Methods))
+		teq(t, "Get returns the user for id.\n", store.Methods[0].Doc)
+		teq(t, "", store.Methods[0].Recv)
+		teq(t, true, store.Methods[1].Variadic)
+	})
+	t.Run("Tags", func(t *testing.T) {
+		tags := parseTags(`json:"a,omitempty"  xml:"b" bad"`)
+		teq(t, 2, len(tags))
+		teq(t, "a", tags[0].Name)
+		teq(t, []string{"omitempty"}, tags[0].Options)
+		teq(t, "b", tags[1].Value)
+		teq(t, 0, len(parseTags(`json:"a`)))
+		teq(t, reflect.StructTag(`json:"a"`).Get("json"), parseTags(`json:"a"`)[0].Value)
+	})
+	t.Run("Template", func(t *testing.T) {
+		tmpl := template.Must(template.New("gen").Funcs(GenFuncs()).Parse(`
+package {{.Package.Name}}
+
+{{imports}}
+
+{{range .Package.Structs}}
+// Zero{{.Name}} returns the zero values of each field of {{.Name}}.
+func Zero{{.Name}}() {{.Name}} {
+	return {{.Name}}{
+	{{- range .Fields}}{{if and .Exported (not .Embedded)}}
+		{{.Name}}: {{zero .Type}},
+	{{- end}}{{end}}
+	}
+}
+
+// {{.Name}}Columns returns the db columns of {{.Name}}.
+func {{.Name}}Columns() []string {
+	return []string{ {{- range .Fields}}{{with .Tag.Get "db"}}{{printf "%q" .}},{{end}}{{end}} }
+}
+{{end}}
+{{- range .Package.Interfaces}}{{$iface := .}}{{range .Methods}}
+// {{$iface.Name}}{{.Name}} is the signature of {{$iface.Name}}.{{.Name}}.
+type {{$iface.Name}}{{.Name}} {{qualify .Signature}}
+{{end}}{{end}}
+{{- range .Package.Funcs}}
+var _ = {{.Name}}({{range $i, $p := .Params}}{{if $i}}, {{end}}{{zero $p.Type}}{{end}})
+{{- end}}
+{{import "fmt"}}
+var _ = fmt.Sprint
+`))
+		src, err := Generate(tmpl, pkg)
+		tmust(t, err)
+		exp := `package gen
+
+import (
+	"fmt"
+	"time"
+)
+
+// ZeroUser returns the zero values of each field of User.
+func ZeroUser() User {
+	return User{
+		ID:      0,
+		Name:    "",
+		Created: time.Time{},
+		Avatar:  nil,
+	}
+}
+
+// UserColumns returns the db columns of User.
+func UserColumns() []string {
+	return []string{"user_id,pk"}
+}
+
+// StoreGet is the signature of Store.Get.
+type StoreGet func(id int64) (*User, error)
+
+// StorePut is the signature of Store.Put.
+type StorePut func(users ...*User) error
+
+var _ = Open("", 0)
+
+var _ = fmt.Sprint
+`
+		teq(t, exp, string(src))
+
+		// imports are only those qualified during this call
+		src, err = Generate(tmpl, pkg)
+		tmust(t, err)
+		teq(t, exp, string(src))
+	})
+	t.Run("Packages", func(t *testing.T) {
+		dst, err := ctx.Import("github.com/cstockton/go-srcutil/testdata/stub")
+		tmust(t, err)
+
+		tmpl := template.Must(template.New("gen").Funcs(GenFuncs()).Parse(`
+package {{.Package.Name}}
+
+{{imports}}
+{{range (index .Packages 1).Structs}}
+var _ {{qualify .Type}}
+{{end}}
+{{- range (index .Packages 1).Funcs}}
+var _ {{qualify .Signature}}
+{{- end}}
+`))
+		src, err := Generate(tmpl, dst, pkg)
+		tmust(t, err)
+		exp := `package stub
+
+import (
+	"github.com/cstockton/go-srcutil/testdata/gen"
+	"time"
+)
+
+var _ gen.User
+
+var _ func(name string, timeout time.Duration) (gen.Store, error)
+`
+		teq(t, exp, string(src))
+	})
+	t.Run("TypeParams", func(t *testing.T) {
+		generic, err := ctx.Import(gen + "/generic")
+		tmust(t, err)
+
+		tmpl := template.Must(template.New("gen").Funcs(GenFuncs()).Parse(`
+package {{.Package.Name}}
+{{range .Package.Structs}}
+func (p {{.Name}}[K, V]) zero() {{.Name}}[K, V] {
+	return {{.Name}}[K, V]{
+	{{- range .Fields}}
+		{{.Name}}: {{zero .Type}},
+	{{- end}}
+	}
+}
+{{end}}`))
+		src, err := Generate(tmpl, generic)
+		tmust(t, err)
+		exp := `package generic
+
+func (p Pair[K, V]) zero() Pair[K, V] {
+	return Pair[K, V]{
+		Key:   *new(K),
+		Value: *new(V),
+		Count: 0,
+	}
+}
+`
+		teq(t, exp, string(src))
+	})
+	t.Run("Failure", func(t *testing.T) {
+		_, err := Generate(template.Must(template.New("gen").Parse(``)))
+		if err == nil {
+			t.Fatal("exp non-nil err")
+		}
+
+		tmpl := template.Must(template.New("gen").Funcs(GenFuncs()).Parse(
+			`package {{.Package.Name}} func {`))
+		src, err := Generate(tmpl, pkg)
+		if err == nil {
+			t.Fatal("exp non-nil err")
+		}
+		if !strings.Contains(string(src), "package gen func {") {
+			t.Fatalf("exp unformatted source; got %q", src)
+		}
+	})
+}
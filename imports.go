@@ -0,0 +1,178 @@
+package srcutil
+
+import (
+	"bytes"
+	"go/token"
+	"go/types"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Imports manages the imports of a generated file within the package with
+// the import path Path. Each imported path is given a unique name, when the
+// package name of a path is already in use it is aliased by appending the
+// lowest number which makes it unique, I.E. "rand2".
+//
+// The qualifier returned by Qualifier adds the path of each package it is
+// called with, allowing the imports of generated code to be collected while
+// printing types.
+type Imports struct {
+	Path string
+
+	names    map[string]string // path to name used
+	pkgNames map[string]string // path to package name
+	used     map[string]bool
+}
+
+// NewImports returns an Imports for the package with the import path dstPath.
+func NewImports(dstPath string) *Imports {
+	return &Imports{
+		Path:     dstPath,
+		names:    make(map[string]string),
+		pkgNames: make(map[string]string),
+		used:     make(map[string]bool),
+	}
+}
+
+// FileImports returns an Imports for a file within this package, the names
+// declared within its package scope are reserved.
+func (p *Package) FileImports() (*Imports, error) {
+	if err := p.init(); err != nil {
+		return nil, err
+	}
+	im := NewImports(p.ImportPath)
+	im.Reserve(p.tc.typesPkg.Scope().Names()...)
+	return im, nil
+}
+
+// Reserve prevents names from being used for imports, such as the names of
+// declarations within the generated file.
+func (im *Imports) Reserve(names ...string) {
+	for _, name := range names {
+		im.used[name] = true
+	}
+}
+
+// Add adds the import path importPath with the package name pkgName and
+// returns the name it is referred to by, which is empty for the path of the
+// destination package. When pkgName is empty it is the last element of
+// importPath which is not a major version suffix such as "v2", with any "go-"
+// prefix or ".v2" suffix removed and other invalid characters replaced by
+// underscores. Names which are keywords or start with a digit are prefixed by
+// "pkg_".
+func (im *Imports) Add(importPath, pkgName string) string {
+	if importPath == im.Path {
+		return ``
+	}
+	if name, ok := im.names[importPath]; ok {
+		return name
+	}
+	im.pkgNames[importPath] = pkgName
+	if len(pkgName) == 0 {
+		// the package name is unknown, so it is only assumed by Decl to be the
+		// last element
+		im.pkgNames[importPath] = path.Base(importPath)
+		pkgName = importName(importPath)
+	}
+	name := pkgName
+	for i := 2; im.used[name]; i++ {
+		name = pkgName + strconv.Itoa(i)
+	}
+	im.used[name] = true
+	im.names[importPath] = name
+	return name
+}
+
+// Name returns the name importPath is referred to by and true if it has been
+// added.
+func (im *Imports) Name(importPath string) (string, bool) {
+	name, ok := im.names[importPath]
+	return name, ok
+}
+
+// Qualifier returns a types.Qualifier which adds the path of each package it
+// is called with and returns the name it is referred to by.
+func (im *Imports) Qualifier() types.Qualifier {
+	return func(pkg *types.Package) string {
+		return im.Add(pkg.Path(), pkg.Name())
+	}
+}
+
+// TypeString returns typ as Go source, adding the packages it refers to.
+func (im *Imports) TypeString(typ types.Type) string {
+	return types.TypeString(typ, im.Qualifier())
+}
+
+// Len returns the number of imports.
+func (im *Imports) Len() int {
+	return len(im.names)
+}
+
+// Paths returns the imported paths in sorted order.
+func (im *Imports) Paths() []string {
+	paths := make([]string, 0, len(im.names))
+	for importPath := range im.names {
+		paths = append(paths, importPath)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Decl returns the import declaration as Go source, paths are aliased when
+// their name differs from their package name, or from their last element when
+// the package name was not given to Add. It is empty when there are no
+// imports.
+func (im *Imports) Decl() []byte {
+	if len(im.names) == 0 {
+		return nil
+	}
+	var buf bytes.Buffer
+	buf.WriteString("import (\n")
+	for _, importPath := range im.Paths() {
+		buf.WriteString("\t")
+		if name := im.names[importPath]; name != im.pkgNames[importPath] {
+			buf.WriteString(name + " ")
+		}
+		buf.WriteString(strconv.Quote(importPath) + "\n")
+	}
+	buf.WriteString(")\n")
+	return buf.Bytes()
+}
+
+// String implements fmt.Stringer by returning the result of Decl.
+func (im *Imports) String() string {
+	return string(im.Decl())
+}
+
+// importName returns the conventional package name of importPath, which is
+// prefixed by "pkg_" when it would not be a valid identifier or is a keyword.
+func importName(importPath string) string {
+	name := path.Base(importPath)
+	if isMajorVersion(name) {
+		if dir := path.Dir(importPath); dir != "." {
+			name = path.Base(dir)
+		}
+	}
+	if i := strings.LastIndex(name, "."); i > 0 && isMajorVersion(name[i+1:]) {
+		name = name[:i] // gopkg.in style, I.E. "yaml.v3"
+	}
+	name = strings.TrimPrefix(name, "go-")
+	name = strings.Map(func(r rune) rune {
+		if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return '_'
+		}
+		return r
+	}, name)
+	if !token.IsIdentifier(name) || token.IsKeyword(name) {
+		name = "pkg_" + name
+	}
+	return name
+}
+
+// isMajorVersion reports whether s is a major version such as "v2".
+func isMajorVersion(s string) bool {
+	return len(s) > 1 && s[0] == 'v' && strings.Trim(s[1:], "0123456789") == ``
+}
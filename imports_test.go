@@ -0,0 +1,84 @@
+package srcutil
+
+import (
+	"go/types"
+	"strings"
+	"testing"
+)
+
+func TestImports(t *testing.T) {
+	t.Run("Add", func(t *testing.T) {
+		im := NewImports("example.com/dst")
+		teq(t, "", im.Add("example.com/dst", "dst"))
+		teq(t, "rand", im.Add("math/rand", "rand"))
+		teq(t, "rand", im.Add("math/rand", "rand"))
+		teq(t, "rand2", im.Add("crypto/rand", "rand"))
+		teq(t, "rand3", im.Add("math/rand/v2", ""))
+		teq(t, "yaml", im.Add("gopkg.in/yaml.v3", ""))
+		teq(t, "srcutil", im.Add("github.com/cstockton/go-srcutil", ""))
+		teq(t, 5, im.Len())
+
+		name, ok := im.Name("crypto/rand")
+		teq(t, "rand2", name)
+		teq(t, true, ok)
+		_, ok = im.Name("fmt")
+		teq(t, false, ok)
+
+		exp := `import (
+	rand2 "crypto/rand"
+	srcutil "github.com/cstockton/go-srcutil"
+	yaml "gopkg.in/yaml.v3"
+	"math/rand"
+	rand3 "math/rand/v2"
+)
+`
+		teq(t, exp, im.String())
+		teq(t, 0, len(NewImports("").Decl()))
+	})
+	t.Run("Names", func(t *testing.T) {
+		for path, exp := range map[string]string{
+			"example.com/go":          "pkg_go",
+			"example.com/type":        "pkg_type",
+			"example.com/3d":          "pkg_3d",
+			"example.com/go-sql/v2":   "sql",
+			"gopkg.in/check.v1":       "check",
+			"example.com/json+schema": "json_schema",
+		} {
+			teq(t, exp, NewImports("").Add(path, ""))
+		}
+	})
+	t.Run("Reserve", func(t *testing.T) {
+		im := NewImports("")
+		im.Reserve("fmt")
+		teq(t, "fmt2", im.Add("fmt", "fmt"))
+	})
+	t.Run("Qualifier", func(t *testing.T) {
+		pkg, err := FromWorkDir().Import("github.com/cstockton/go-srcutil/testdata/stub")
+		tmust(t, err)
+		ms, err := pkg.MethodSet("Store")
+		tmust(t, err)
+
+		im, err := pkg.FileImports()
+		tmust(t, err)
+		get := ms.Methods["Get"]
+		teq(t, "func (Store).Get(ctx context.Context, key string) (*Item, error)",
+			get.Render(im.Qualifier()))
+		teq(t, []string{"context"}, im.Paths())
+
+		// names declared within the package are reserved
+		im.Add("example.com/store", "Item")
+		name, _ := im.Name("example.com/store")
+		teq(t, "Item2", name)
+
+		im = NewImports("")
+		teq(t, "*stub.Item", im.TypeString(types.NewPointer(
+			pkg.tc.typesPkg.Scope().Lookup("Item").Type())))
+		teq(t, []string{"github.com/cstockton/go-srcutil/testdata/stub"}, im.Paths())
+
+		lines := strings.Split(ms.Render(im.Qualifier()), "\n")
+		teq(t, ms.Len(), len(lines))
+		teq(t, "func (io.Closer).Close() error", lines[0])
+		teq(t, []string{"context", "github.com/cstockton/go-srcutil/testdata/stub", "io"},
+			im.Paths())
+	})
+}
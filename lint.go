@@ -0,0 +1,432 @@
+package srcutil
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"sort"
+)
+
+// LintRule is a check run on initialized packages by a Linter.
+type LintRule struct {
+	// Name identifies the rule within a LintConfig and is the Category of the
+	// lints it reports, I.E. "receiver-names".
+	Name string
+	Doc  string
+
+	// Default is true when the rule runs without being enabled by name.
+	Default bool
+
+	// Check returns the problems found within p, the Package and Category of
+	// each Lint are set for it when empty.
+	Check func(p *Package) ([]Lint, error)
+}
+
+// Lint is a problem reported by a LintRule along with any fixes it suggests.
+type Lint struct {
+	Diagnostic
+	Package *Package
+	Fixes   []Fix
+}
+
+// Fix is a suggested change which resolves a Lint.
+type Fix struct {
+	Message string
+	Edits   []TextEdit
+}
+
+// TextEdit replaces the source between Pos and End with NewText, positions
+// are found within the file by their Offset so they may come from any parse
+// of the package.
+type TextEdit struct {
+	Pos, End token.Position
+	NewText  string
+}
+
+// LintConfig selects the rules a Linter runs, it may be decoded from JSON such
+// as {"enable": ["all"], "disable": ["undocumented"]}.
+type LintConfig struct {
+	// Enable names the rules to run in addition to those enabled by default,
+	// or "all" to run every rule.
+	Enable []string `json:"enable,omitempty"`
+
+	// Disable names the rules not to run, it takes precedence over Enable.
+	Disable []string `json:"disable,omitempty"`
+}
+
+// LintRules are the built-in rules, used by NewLinter when it is not given
+// any rules.
+var LintRules = []*LintRule{
+	UndocumentedRule, UnexportedReturnRule, ReceiverNamesRule, ReceiverKindsRule,
+}
+
+// Linter runs a set of rules on packages.
+type Linter struct {
+	Rules []*LintRule
+}
+
+// NewLinter returns a Linter running the rules enabled by config, which are
+// chosen from rules or LintRules when none are given. An error is returned
+// when config names a rule which does not exist.
+func NewLinter(config LintConfig, rules ...*LintRule) (*Linter, error) {
+	if len(rules) == 0 {
+		rules = LintRules
+	}
+	names := make(map[string]bool)
+	for _, r := range rules {
+		names[r.Name] = true
+	}
+	set := func(list []string) (map[string]bool, error) {
+		out := make(map[string]bool)
+		for _, name := range list {
+			if !names[name] && name != "all" {
+				return nil, fmt.Errorf("lint: unknown rule %q", name)
+			}
+			out[name] = true
+		}
+		return out, nil
+	}
+	enable, err := set(config.Enable)
+	if err != nil {
+		return nil, err
+	}
+	disable, err := set(config.Disable)
+	if err != nil {
+		return nil, err
+	}
+
+	l := new(Linter)
+	for _, r := range rules {
+		if (r.Default || enable[r.Name] || enable["all"]) &&
+			!disable[r.Name] && !disable["all"] {
+			l.Rules = append(l.Rules, r)
+		}
+	}
+	return l, nil
+}
+
+// Lint runs each rule on each of pkgs and returns the lints they report
+// ordered by position.
+func (l *Linter) Lint(pkgs ...*Package) ([]Lint, error) {
+	var out []Lint
+	for _, p := range pkgs {
+		if err := p.init(); err != nil {
+			return nil, err
+		}
+		for _, r := range l.Rules {
+			lints, err := r.Check(p)
+			if err != nil {
+				return nil, fmt.Errorf("lint: rule %v: %v", r.Name, err)
+			}
+			for _, lint := range lints {
+				if lint.Package == nil {
+					lint.Package = p
+				}
+				if len(lint.Category) == 0 {
+					lint.Category = r.Name
+				}
+				out = append(out, lint)
+			}
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return diagnosticLess(out[i].Diagnostic, out[j].Diagnostic)
+	})
+	return out, nil
+}
+
+// ApplyFixes returns a Patch applying the first Fix of each of lints which
+// has one. The edits of the fixes may not overlap.
+func ApplyFixes(lints []Lint) (*Patch, error) {
+	var editors []*Editor
+	byPkg := make(map[*Package]*Editor)
+	for _, lint := range lints {
+		if len(lint.Fixes) == 0 {
+			continue
+		}
+		e, ok := byPkg[lint.Package]
+		if !ok {
+			var err error
+			if e, err = lint.Package.Editor(); err != nil {
+				return nil, err
+			}
+			byPkg[lint.Package] = e
+			editors = append(editors, e)
+		}
+		for _, te := range lint.Fixes[0].Edits {
+			f := e.File(te.Pos.Filename)
+			if f == nil {
+				return nil, fmt.Errorf("lint: fix for %v is not within package %v",
+					te.Pos, lint.Package.Name)
+			}
+			tf := e.Fset.File(f.Pos())
+			if te.Pos.Offset > te.End.Offset || te.End.Offset > tf.Size() {
+				return nil, fmt.Errorf("lint: fix for %v has an invalid range", te.Pos)
+			}
+			if err := e.Edit(tf.Pos(te.Pos.Offset), tf.Pos(te.End.Offset), te.NewText); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return NewPatch(editors...), nil
+}
+
+// UndocumentedRule reports exported declarations without a doc comment,
+// excluding struct fields, interface methods and those documented by their
+// group.
+var UndocumentedRule = &LintRule{
+	Name:    "undocumented",
+	Doc:     "report exported declarations without a doc comment",
+	Default: true,
+	Check: func(p *Package) ([]Lint, error) {
+		d := Docs{p}
+		var out []Lint
+		for _, decl := range d.decls() {
+			if len(decl.Doc) > 0 || decl.Kind == "field" || (decl.Kind == "method" && decl.Group) {
+				continue
+			}
+			msg := fmt.Sprintf("exported %s %s should have a doc comment", decl.Kind, decl.Name)
+			if decl.Kind == "package" {
+				if !decl.Pos.IsValid() {
+					// no file has a package clause outside of the tests
+					continue
+				}
+				msg = fmt.Sprintf("package %s should have a package comment", decl.Name)
+			}
+			out = append(out, Lint{Diagnostic: Diagnostic{Pos: decl.Pos, Message: msg}})
+		}
+		return out, nil
+	},
+}
+
+// UnexportedReturnRule reports exported funcs and methods returning an
+// unexported type of their package, which callers may not name.
+var UnexportedReturnRule = &LintRule{
+	Name:    "unexported-return",
+	Doc:     "report exported funcs returning unexported types",
+	Default: true,
+	Check: func(p *Package) ([]Lint, error) {
+		var out []Lint
+		for _, fd := range p.funcDecls() {
+			fn, ok := p.tc.typesInfo.Defs[fd.Name].(*types.Func)
+			if !ok || !fn.Exported() || isTestFile(p.tc.fileSet.Position(fd.Pos())) {
+				continue
+			}
+			sig := fn.Type().(*types.Signature)
+			name := fn.Name()
+			if recv := recvNamed(sig); recv != nil {
+				if !recv.Obj().Exported() {
+					continue
+				}
+				name = recv.Obj().Name() + "." + name
+			}
+
+			results := sig.Results()
+			for i := 0; i < results.Len(); i++ {
+				named := unexportedNamed(results.At(i).Type(), p.tc.typesPkg)
+				if named == nil {
+					continue
+				}
+				out = append(out, Lint{Diagnostic: Diagnostic{
+					Pos: p.tc.fileSet.Position(fd.Type.Results.Pos()),
+					Message: fmt.Sprintf("exported func %s returns unexported type %s",
+						name, named.Obj().Name())}})
+				break
+			}
+		}
+		return out, nil
+	},
+}
+
+// ReceiverNamesRule reports methods with a receiver name differing from the
+// name used by most of the methods of the same type, suggesting it is renamed.
+var ReceiverNamesRule = &LintRule{
+	Name:    "receiver-names",
+	Doc:     "report receiver names which differ across the methods of a type",
+	Default: true,
+	Check: func(p *Package) ([]Lint, error) {
+		var out []Lint
+		for _, methods := range p.methodDecls() {
+			// the most common name, ties go to the first declared
+			counts := make(map[string]int)
+			var want string
+			for _, fd := range methods {
+				if name := recvIdent(fd); name != nil {
+					counts[name.Name]++
+					if counts[name.Name] > counts[want] {
+						want = name.Name
+					}
+				}
+			}
+			if len(counts) < 2 {
+				continue
+			}
+
+			for _, fd := range methods {
+				ident := recvIdent(fd)
+				if ident == nil || ident.Name == want {
+					continue
+				}
+				lint := Lint{Diagnostic: Diagnostic{
+					Pos: p.tc.fileSet.Position(ident.Pos()),
+					Message: fmt.Sprintf("receiver name %s of method %s.%s should be %s like the other methods of %s",
+						ident.Name, recvTypeName(fd.Recv.List[0].Type), fd.Name.Name, want, recvTypeName(fd.Recv.List[0].Type))}}
+				if edits := p.renameRecv(fd, ident, want); edits != nil {
+					lint.Fixes = []Fix{{Message: "rename receiver to " + want, Edits: edits}}
+				}
+				out = append(out, lint)
+			}
+		}
+		return out, nil
+	},
+}
+
+// ReceiverKindsRule reports methods with a value receiver when other methods
+// of the same type have pointer receivers, suggesting a pointer receiver.
+// Fixing it removes the method from the method set of the value type.
+var ReceiverKindsRule = &LintRule{
+	Name:    "receiver-kinds",
+	Doc:     "report types with both value and pointer receivers",
+	Default: true,
+	Check: func(p *Package) ([]Lint, error) {
+		var out []Lint
+		for _, methods := range p.methodDecls() {
+			var values []*ast.FuncDecl
+			for _, fd := range methods {
+				if _, ok := fd.Recv.List[0].Type.(*ast.StarExpr); !ok {
+					values = append(values, fd)
+				}
+			}
+			if len(values) == 0 || len(values) == len(methods) {
+				continue
+			}
+
+			for _, fd := range values {
+				pos := p.tc.fileSet.Position(fd.Recv.List[0].Type.Pos())
+				out = append(out, Lint{
+					Diagnostic: Diagnostic{Pos: pos, Message: fmt.Sprintf(
+						"method %s.%s has a value receiver while other methods of %s have pointer receivers",
+						recvTypeName(fd.Recv.List[0].Type), fd.Name.Name, recvTypeName(fd.Recv.List[0].Type))},
+					Fixes: []Fix{{Message: "use a pointer receiver",
+						Edits: []TextEdit{{Pos: pos, End: pos, NewText: "*"}}}},
+				})
+			}
+		}
+		return out, nil
+	},
+}
+
+// funcDecls returns the func declarations of the package ordered by position,
+// including those of the tests of the package.
+func (p *Package) funcDecls() []*ast.FuncDecl {
+	var out []*ast.FuncDecl
+	for _, f := range p.astFiles(p.tc.astPkg) {
+		for _, decl := range f.Decls {
+			if fd, ok := decl.(*ast.FuncDecl); ok {
+				out = append(out, fd)
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Pos() < out[j].Pos() })
+	return out
+}
+
+// methodDecls returns the method declarations of the package grouped by the
+// name of their receiver type, ordered by position.
+func (p *Package) methodDecls() [][]*ast.FuncDecl {
+	var out [][]*ast.FuncDecl
+	index := make(map[string]int)
+	for _, fd := range p.funcDecls() {
+		if fd.Recv == nil || len(fd.Recv.List) == 0 {
+			continue
+		}
+		name := recvTypeName(fd.Recv.List[0].Type)
+		i, ok := index[name]
+		if !ok {
+			i = len(out)
+			index[name] = i
+			out = append(out, nil)
+		}
+		out[i] = append(out[i], fd)
+	}
+	return out
+}
+
+// recvIdent returns the name of the receiver of fd, or nil if it is unnamed
+// or blank.
+func recvIdent(fd *ast.FuncDecl) *ast.Ident {
+	names := fd.Recv.List[0].Names
+	if len(names) == 0 || names[0].Name == "_" {
+		return nil
+	}
+	return names[0]
+}
+
+// renameRecv returns the edits renaming the receiver ident of fd to name, or
+// nil if name is already used within fd.
+func (p *Package) renameRecv(fd *ast.FuncDecl, ident *ast.Ident, name string) []TextEdit {
+	obj := p.tc.typesInfo.Defs[ident]
+	var idents []*ast.Ident
+	used := false
+	ast.Inspect(fd, func(n ast.Node) bool {
+		if id, ok := n.(*ast.Ident); ok {
+			switch {
+			case id.Name == name:
+				used = true
+			case id == ident || (obj != nil && p.tc.typesInfo.Uses[id] == obj):
+				idents = append(idents, id)
+			}
+		}
+		return !used
+	})
+	if used {
+		return nil
+	}
+
+	edits := make([]TextEdit, len(idents))
+	for i, id := range idents {
+		edits[i] = TextEdit{Pos: p.tc.fileSet.Position(id.Pos()),
+			End: p.tc.fileSet.Position(id.End()), NewText: name}
+	}
+	return edits
+}
+
+// recvNamed returns the named type of the receiver of sig, or nil if sig is
+// not a method.
+func recvNamed(sig *types.Signature) *types.Named {
+	if sig.Recv() == nil {
+		return nil
+	}
+	typ := sig.Recv().Type()
+	if ptr, ok := typ.(*types.Pointer); ok {
+		typ = ptr.Elem()
+	}
+	named, _ := typ.(*types.Named)
+	return named
+}
+
+// unexportedNamed returns the unexported named type of pkg typ refers to
+// through any pointers, slices, arrays, maps or channels, or nil.
+func unexportedNamed(typ types.Type, pkg *types.Package) *types.Named {
+	switch t := typ.(type) {
+	case *types.Named:
+		if t.Obj().Pkg() == pkg && !t.Obj().Exported() {
+			return t
+		}
+	case *types.Pointer:
+		return unexportedNamed(t.Elem(), pkg)
+	case *types.Slice:
+		return unexportedNamed(t.Elem(), pkg)
+	case *types.Array:
+		return unexportedNamed(t.Elem(), pkg)
+	case *types.Chan:
+		return unexportedNamed(t.Elem(), pkg)
+	case *types.Map:
+		if named := unexportedNamed(t.Key(), pkg); named != nil {
+			return named
+		}
+		return unexportedNamed(t.Elem(), pkg)
+	}
+	return nil
+}
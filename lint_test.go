@@ -0,0 +1,94 @@
+package srcutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestLinter(t *testing.T) {
+	const lint = "github.com/cstockton/go-srcutil/testdata/lint"
+	pkg, err := FromWorkDir().Import(lint)
+	tmust(t, err)
+
+	lines := func(lints []Lint) (out []string) {
+		for _, l := range lints {
+			out = append(out, fmt.Sprintf("%d %s: %s", l.Pos.Line, l.Category, l.Message))
+		}
+		return
+	}
+
+	t.Run("Default", func(t *testing.T) {
+		l, err := NewLinter(LintConfig{})
+		tmust(t, err)
+		teq(t, 4, len(l.Rules))
+
+		lints, err := l.Lint(pkg)
+		tmust(t, err)
+		teq(t, []string{
+			"10 receiver-kinds: method Counter.Get has a value receiver while other methods of Counter have pointer receivers",
+			"15 receiver-names: receiver name counter of method Counter.Add should be c like the other methods of Counter",
+			"24 undocumented: exported type Undocumented should have a doc comment",
+			"27 unexported-return: exported func New returns unexported type impl",
+			"33 receiver-kinds: method impl.value has a value receiver while other methods of impl have pointer receivers",
+		}, lines(lints))
+		teq(t, pkg, lints[0].Package)
+	})
+	t.Run("Config", func(t *testing.T) {
+		var config LintConfig
+		tmust(t, json.Unmarshal(
+			[]byte(`{"disable": ["receiver-kinds", "undocumented"]}`), &config))
+		l, err := NewLinter(config)
+		tmust(t, err)
+
+		lints, err := l.Lint(pkg)
+		tmust(t, err)
+		teq(t, 2, len(lints))
+		teq(t, "receiver-names", lints[0].Category)
+		teq(t, "unexported-return", lints[1].Category)
+	})
+	t.Run("Rules", func(t *testing.T) {
+		optional := &LintRule{Name: "optional", Check: func(p *Package) ([]Lint, error) {
+			return []Lint{{Diagnostic: Diagnostic{Message: "optional " + p.Name}}}, nil
+		}}
+
+		l, err := NewLinter(LintConfig{}, optional, UndocumentedRule)
+		tmust(t, err)
+		teq(t, []*LintRule{UndocumentedRule}, l.Rules)
+
+		l, err = NewLinter(LintConfig{Enable: []string{"all"},
+			Disable: []string{"undocumented"}}, optional, UndocumentedRule)
+		tmust(t, err)
+		teq(t, []*LintRule{optional}, l.Rules)
+
+		lints, err := l.Lint(pkg)
+		tmust(t, err)
+		teq(t, []string{"0 optional: optional lint"}, lines(lints))
+
+		if _, err := NewLinter(LintConfig{Enable: []string{"missing"}}); err == nil {
+			t.Errorf("exp error for unknown rule")
+		}
+	})
+	t.Run("ApplyFixes", func(t *testing.T) {
+		l, err := NewLinter(LintConfig{})
+		tmust(t, err)
+		lints, err := l.Lint(pkg)
+		tmust(t, err)
+
+		patch, err := ApplyFixes(lints)
+		tmust(t, err)
+		diff, err := patch.Diff()
+		tmust(t, err)
+		for _, exp := range []string{
+			"-func (c Counter) Get() int {\n+func (c *Counter) Get() int {\n",
+			"-func (counter *Counter) Add(i int) {\n-\tcounter.n += i\n" +
+				"+func (c *Counter) Add(i int) {\n+\tc.n += i\n",
+			"-func (i impl) value() {}\n+func (i *impl) value() {}\n",
+		} {
+			if !strings.Contains(diff, exp) {
+				t.Errorf("exp diff to contain:\n%v\ngot:\n%v", exp, diff)
+			}
+		}
+	})
+}
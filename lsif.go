@@ -0,0 +1,453 @@
+package srcutil
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// LSIFVersion is the version of the Language Server Index Format written by
+// WriteLSIF.
+const LSIFVersion = "0.4.3"
+
+// WriteLSIF writes an LSIF index of the given packages to w as a stream of
+// JSON lines, see https://microsoft.github.io/language-server-protocol/specifications/lsif/0.4.0/specification/.
+// LSIF is used rather than SCIP since it is plain JSON and needs no protobuf
+// dependencies.
+//
+// Each identifier within the packages has a definition, references and hover
+// result containing its signature and doc comment. References between the
+// given packages resolve to the same result set, so indexing a program along
+// with its dependencies gives precise cross package navigation. Named types
+// are linked to the interfaces they implement from the package they are
+// declared in or its imports, along with their methods. Package level
+// identifiers and methods have monikers of the "go" scheme named
+// "import/path:Name" or "import/path:Type.Method". Positions are written in
+// utf-16 code units as declared by the positionEncoding of the metadata.
+func WriteLSIF(w io.Writer, root string, pkgs []*Package) error {
+	bw := bufio.NewWriter(w)
+	x := &lsif{
+		enc:     json.NewEncoder(bw),
+		docs:    make(map[string]int),
+		src:     make(map[string][]byte),
+		ranges:  make(map[int][]int),
+		results: make(map[string]*lsifResult),
+	}
+
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return err
+	}
+	x.emit(&lsifElement{Type: "vertex", Label: "metaData", Version: LSIFVersion,
+		ProjectRoot: lsifURI(abs), PositionEncoding: "utf-16",
+		ToolInfo: &lsifToolInfo{Name: "srcutil"}})
+	project := x.emit(&lsifElement{Type: "vertex", Label: "project", Kind: "go"})
+
+	for _, pkg := range pkgs {
+		if err := pkg.init(); err != nil {
+			return err
+		}
+		x.pkg(pkg)
+	}
+	for _, pkg := range pkgs {
+		x.implementations(pkg)
+	}
+	x.finish(project)
+	if x.err != nil {
+		return x.err
+	}
+	return bw.Flush()
+}
+
+type lsif struct {
+	enc     *json.Encoder
+	err     error
+	id      int
+	docs    map[string]int    // filename to document id
+	src     map[string][]byte // of docs, for utf-16 columns
+	order   []string          // of docs
+	ranges  map[int][]int     // document id to range ids
+	keys    []string          // of results
+	results map[string]*lsifResult
+}
+
+// lsifResult is the result set of a single object found by its position.
+type lsifResult struct {
+	obj   types.Object
+	hover []interface{}
+	defs  []lsifRange
+	refs  []lsifRange
+	impls []string // keys of the results implementing this one
+
+	// moniker is the identifier of the moniker, it is empty for objects that
+	// are not package level identifiers or methods.
+	moniker string
+}
+
+type lsifRange struct {
+	doc, id int
+}
+
+type lsifToolInfo struct {
+	Name string `json:"name"`
+}
+
+type lsifPos struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type lsifHover struct {
+	Contents []interface{} `json:"contents"`
+}
+
+// lsifElement is a vertex or edge, only the properties for its label are set.
+type lsifElement struct {
+	ID    int    `json:"id"`
+	Type  string `json:"type"`
+	Label string `json:"label"`
+
+	Version          string        `json:"version,omitempty"`
+	ProjectRoot      string        `json:"projectRoot,omitempty"`
+	PositionEncoding string        `json:"positionEncoding,omitempty"`
+	ToolInfo         *lsifToolInfo `json:"toolInfo,omitempty"`
+	Kind             string        `json:"kind,omitempty"`
+	URI              string        `json:"uri,omitempty"`
+	LanguageID       string        `json:"languageId,omitempty"`
+	Start            *lsifPos      `json:"start,omitempty"`
+	End              *lsifPos      `json:"end,omitempty"`
+	Result           *lsifHover    `json:"result,omitempty"`
+	Scheme           string        `json:"scheme,omitempty"`
+	Identifier       string        `json:"identifier,omitempty"`
+
+	OutV     int    `json:"outV,omitempty"`
+	InV      int    `json:"inV,omitempty"`
+	InVs     []int  `json:"inVs,omitempty"`
+	Document int    `json:"document,omitempty"`
+	Property string `json:"property,omitempty"`
+}
+
+func lsifURI(path string) string {
+	return "file://" + filepath.ToSlash(path)
+}
+
+func (x *lsif) emit(el *lsifElement) int {
+	x.id++
+	el.ID = x.id
+	if x.err == nil {
+		x.err = x.enc.Encode(el)
+	}
+	return el.ID
+}
+
+func (x *lsif) edge(label string, out int, in ...int) {
+	el := &lsifElement{Type: "edge", Label: label, OutV: out}
+	if len(in) == 1 && label != "contains" {
+		el.InV = in[0]
+	} else {
+		el.InVs = in
+	}
+	x.emit(el)
+}
+
+// key returns the position of obj as a string when it has one, which is the
+// same for each package that refers to obj. Objects imported from export data
+// have no position, so they are keyed by the path of their package and their
+// path within it, I.E. "bufio:Reader.Read".
+func (x *lsif) key(fset *token.FileSet, obj types.Object) string {
+	if pos := fset.Position(obj.Pos()); len(pos.Filename) > 0 {
+		return fmt.Sprintf("%s:%d", pos.Filename, pos.Offset)
+	}
+	if name := lsifPath(obj); len(name) > 0 {
+		return obj.Pkg().Path() + ":" + name
+	}
+	return fmt.Sprintf("%p", obj) // only the same within a single package
+}
+
+func (x *lsif) result(p *Package, obj types.Object) *lsifResult {
+	key := x.key(p.tc.fileSet, obj)
+	if res, ok := x.results[key]; ok {
+		return res
+	}
+	res := &lsifResult{obj: obj}
+	x.keys = append(x.keys, key)
+	x.results[key] = res
+	return res
+}
+
+func (x *lsif) pkg(p *Package) {
+	qual := types.RelativeTo(p.tc.typesPkg)
+	docs := make(map[string]string)
+	d := Docs{p}
+	for _, decl := range d.decls() {
+		docs[fmt.Sprintf("%s:%d", decl.Pos.Filename, decl.Pos.Offset)] = decl.Doc
+	}
+
+	type occurrence struct {
+		ident *ast.Ident
+		obj   types.Object
+		def   bool
+	}
+	var occs []occurrence
+	for ident, obj := range p.tc.typesInfo.Defs {
+		if obj != nil && obj.Pkg() != nil {
+			occs = append(occs, occurrence{ident, obj, true})
+		}
+	}
+	for ident, obj := range p.tc.typesInfo.Uses {
+		if _, ok := p.tc.typesInfo.Defs[ident]; !ok && obj.Pkg() != nil {
+			occs = append(occs, occurrence{ident, obj, false})
+		}
+	}
+	sort.Slice(occs, func(i, j int) bool { return occs[i].ident.Pos() < occs[j].ident.Pos() })
+
+	for _, occ := range occs {
+		pos := p.tc.fileSet.Position(occ.ident.Pos())
+		doc, ok := x.docs[pos.Filename]
+		if !ok {
+			doc = x.emit(&lsifElement{Type: "vertex", Label: "document",
+				URI: lsifURI(pos.Filename), LanguageID: "go"})
+			x.docs[pos.Filename] = doc
+			x.order = append(x.order, pos.Filename)
+			src, err := os.ReadFile(pos.Filename)
+			if err != nil && x.err == nil {
+				x.err = err
+			}
+			x.src[pos.Filename] = src
+		}
+		start := lsifPos{Line: pos.Line - 1, Character: lsifColumn(x.src[pos.Filename], pos)}
+		end := lsifPos{Line: start.Line, Character: start.Character + utf16Len(occ.ident.Name)}
+		id := x.emit(&lsifElement{Type: "vertex", Label: "range", Start: &start, End: &end})
+		x.ranges[doc] = append(x.ranges[doc], id)
+
+		res := x.result(p, occ.obj)
+		if !occ.def {
+			res.refs = append(res.refs, lsifRange{doc, id})
+			if len(res.hover) == 0 {
+				// declared outside of the index, it may be replaced if the
+				// declaration is found later on.
+				res.hover = []interface{}{map[string]string{
+					"language": "go", "value": types.ObjectString(occ.obj, qual)}}
+				if name := lsifName(occ.obj); len(name) > 0 && occ.obj.Exported() {
+					res.moniker = occ.obj.Pkg().Path() + ":" + name
+				}
+			}
+			continue
+		}
+		res.defs = append(res.defs, lsifRange{doc, id})
+		res.hover = []interface{}{map[string]string{
+			"language": "go", "value": types.ObjectString(occ.obj, qual)}}
+		if text := docs[x.key(p.tc.fileSet, occ.obj)]; len(text) > 0 {
+			res.hover = append(res.hover, text)
+		}
+		if name := lsifName(occ.obj); len(name) > 0 {
+			res.moniker = p.ImportPath + ":" + name
+		}
+	}
+}
+
+// lsifName returns the name of a package level object or method, I.E. "T.M".
+func lsifName(obj types.Object) string {
+	if obj.Parent() == obj.Pkg().Scope() {
+		return obj.Name()
+	}
+	if f, ok := obj.(*types.Func); ok {
+		recv := f.Type().(*types.Signature).Recv()
+		if recv == nil {
+			return ``
+		}
+		typ := recv.Type()
+		if ptr, ok := typ.(*types.Pointer); ok {
+			typ = ptr.Elem()
+		}
+		if named, ok := typ.(*types.Named); ok {
+			return named.Obj().Name() + "." + obj.Name()
+		}
+	}
+	return ``
+}
+
+// lsifPath is like lsifName but also returns the name of fields, I.E. "T.F".
+// It is empty for objects that are not reachable from the package scope.
+func lsifPath(obj types.Object) string {
+	if name := lsifName(obj); len(name) > 0 {
+		return name
+	}
+	field, ok := obj.(*types.Var)
+	if !ok || !field.IsField() {
+		return ``
+	}
+	scope := obj.Pkg().Scope()
+	for _, name := range scope.Names() {
+		typ, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok {
+			continue
+		}
+		if s, ok := typ.Type().Underlying().(*types.Struct); ok {
+			for i := 0; i < s.NumFields(); i++ {
+				if s.Field(i) == field {
+					return name + "." + field.Name()
+				}
+			}
+		}
+	}
+	return ``
+}
+
+// implementations links the named types declared in p to the interfaces
+// declared in p or the packages it imports.
+func (x *lsif) implementations(p *Package) {
+	var named, ifaces []*types.TypeName
+	scopes := []*types.Scope{p.tc.typesPkg.Scope()}
+	for _, imp := range p.tc.typesPkg.Imports() {
+		scopes = append(scopes, imp.Scope())
+	}
+	for i, scope := range scopes {
+		for _, name := range scope.Names() {
+			obj, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok || obj.IsAlias() {
+				continue
+			}
+			if types.IsInterface(obj.Type()) {
+				ifaces = append(ifaces, obj)
+			} else if i == 0 {
+				named = append(named, obj)
+			}
+		}
+	}
+
+	for _, iface := range ifaces {
+		typ := iface.Type().Underlying().(*types.Interface)
+		if typ.NumMethods() == 0 {
+			continue
+		}
+		for _, obj := range named {
+			ptr := types.NewPointer(obj.Type())
+			if !types.Implements(obj.Type(), typ) && !types.Implements(ptr, typ) {
+				continue
+			}
+			x.implements(p, iface, obj)
+			for i := 0; i < typ.NumMethods(); i++ {
+				m := typ.Method(i)
+				impl, _, _ := types.LookupFieldOrMethod(ptr, false, m.Pkg(), m.Name())
+				if impl != nil {
+					x.implements(p, m, impl)
+				}
+			}
+		}
+	}
+}
+
+func (x *lsif) implements(p *Package, iface, impl types.Object) {
+	res := x.result(p, iface)
+	key := x.key(p.tc.fileSet, impl)
+	for _, k := range res.impls {
+		if k == key {
+			return
+		}
+	}
+	x.result(p, impl)
+	res.impls = append(res.impls, key)
+}
+
+// finish writes the result sets and the containment edges.
+func (x *lsif) finish(project int) {
+	sets := make(map[string]int)
+	for _, key := range x.keys {
+		sets[key] = x.emit(&lsifElement{Type: "vertex", Label: "resultSet"})
+	}
+	for _, key := range x.keys {
+		res, set := x.results[key], sets[key]
+		for _, r := range append(res.defs, res.refs...) {
+			x.edge("next", r.id, set)
+		}
+		if len(res.hover) > 0 {
+			hover := x.emit(&lsifElement{Type: "vertex", Label: "hoverResult",
+				Result: &lsifHover{Contents: res.hover}})
+			x.edge("textDocument/hover", set, hover)
+		}
+		if len(res.defs) > 0 {
+			def := x.emit(&lsifElement{Type: "vertex", Label: "definitionResult"})
+			x.edge("textDocument/definition", set, def)
+			x.items(def, ``, res.defs)
+		}
+		if len(res.defs)+len(res.refs) > 0 {
+			ref := x.emit(&lsifElement{Type: "vertex", Label: "referenceResult"})
+			x.edge("textDocument/references", set, ref)
+			x.items(ref, "definitions", res.defs)
+			x.items(ref, "references", res.refs)
+		}
+		if len(res.impls) > 0 {
+			var defs []lsifRange
+			for _, k := range res.impls {
+				defs = append(defs, x.results[k].defs...)
+			}
+			impl := x.emit(&lsifElement{Type: "vertex", Label: "implementationResult"})
+			x.edge("textDocument/implementation", set, impl)
+			x.items(impl, ``, defs)
+		}
+		if len(res.moniker) > 0 {
+			kind := "export"
+			if len(res.defs) == 0 {
+				kind = "import"
+			}
+			moniker := x.emit(&lsifElement{Type: "vertex", Label: "moniker",
+				Scheme: "go", Identifier: res.moniker, Kind: kind})
+			x.edge("moniker", set, moniker)
+		}
+	}
+
+	var docs []int
+	for _, name := range x.order {
+		doc := x.docs[name]
+		docs = append(docs, doc)
+		x.edge("contains", doc, x.ranges[doc]...)
+	}
+	if len(docs) > 0 {
+		x.edge("contains", project, docs...)
+	}
+}
+
+// items emits an item edge from out for each document of ranges.
+func (x *lsif) items(out int, property string, ranges []lsifRange) {
+	var order []int
+	byDoc := make(map[int][]int)
+	for _, r := range ranges {
+		if _, ok := byDoc[r.doc]; !ok {
+			order = append(order, r.doc)
+		}
+		byDoc[r.doc] = append(byDoc[r.doc], r.id)
+	}
+	for _, doc := range order {
+		x.emit(&lsifElement{Type: "edge", Label: "item", OutV: out,
+			InVs: byDoc[doc], Document: doc, Property: property})
+	}
+}
+
+// lsifColumn returns the zero based column of pos within src in utf-16 code
+// units.
+func lsifColumn(src []byte, pos token.Position) int {
+	start := pos.Offset - (pos.Column - 1)
+	if start < 0 || pos.Offset > len(src) {
+		return pos.Column - 1
+	}
+	return utf16Len(string(src[start:pos.Offset]))
+}
+
+// utf16Len returns the number of utf-16 code units needed to encode s.
+func utf16Len(s string) (n int) {
+	for _, r := range s {
+		n++
+		if r >= 0x10000 {
+			n++
+		}
+	}
+	return
+}
@@ -0,0 +1,144 @@
+package srcutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestWriteLSIF(t *testing.T) {
+	const testdata = "github.com/cstockton/go-srcutil/testdata/"
+	ctx := FromWorkDir()
+	pkgs, err := ctx.ImportAll(
+		testdata+"deprecated/old", testdata+"deprecated/user", testdata+"tags",
+		testdata+"lsif")
+	tmust(t, err)
+
+	var buf bytes.Buffer
+	tmust(t, WriteLSIF(&buf, ".", pkgs))
+
+	elems := make(map[int]lsifElement)
+	out := make(map[int][]lsifElement) // edges by outV
+	var first []lsifElement
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var el lsifElement
+		tmust(t, json.Unmarshal([]byte(line), &el))
+		teq(t, false, el.ID == 0)
+		for _, v := range append([]int{el.OutV, el.InV, el.Document}, el.InVs...) {
+			if _, ok := elems[v]; v != 0 && !ok {
+				t.Fatalf("element %v refers to %v before it was emitted", el.ID, v)
+			}
+		}
+		elems[el.ID] = el
+		if el.Type == "edge" {
+			out[el.OutV] = append(out[el.OutV], el)
+		}
+		if len(first) < 2 {
+			first = append(first, el)
+		}
+	}
+	teq(t, "metaData", first[0].Label)
+	teq(t, LSIFVersion, first[0].Version)
+	teq(t, "project", first[1].Label)
+
+	edge := func(id int, label string) lsifElement {
+		for _, el := range out[id] {
+			if el.Label == label {
+				return el
+			}
+		}
+		t.Fatalf("no %v edge from %v", label, id)
+		return lsifElement{}
+	}
+	// rangeAt finds the range of the identifier at line and column within file.
+	rangeAt := func(file string, line, col int) int {
+		for _, el := range elems {
+			if el.Label != "contains" || elems[el.OutV].Label != "document" {
+				continue
+			}
+			if !strings.HasSuffix(elems[el.OutV].URI, file) {
+				continue
+			}
+			for _, id := range el.InVs {
+				r := elems[id]
+				if r.Start.Line == line-1 && r.Start.Character == col-1 {
+					return id
+				}
+			}
+		}
+		t.Fatalf("no range at %v:%v:%v", file, line, col)
+		return 0
+	}
+
+	t.Run("CrossPackage", func(t *testing.T) {
+		// old.Legacy() within deprecated/user/user.go
+		set := edge(rangeAt("user/user.go", 8, 6), "next").InV
+		def := edge(set, "textDocument/definition").InV
+		item := edge(def, "item")
+		teq(t, true, strings.HasSuffix(elems[item.Document].URI, "old/old.go"))
+		teq(t, rangeAt("old/old.go", 13, 6), item.InVs[0])
+
+		hover := elems[edge(set, "textDocument/hover").InV]
+		teq(t, 2, len(hover.Result.Contents))
+		teq(t, "Legacy is deprecated.\n\nDeprecated: Use Current\ninstead.\n",
+			hover.Result.Contents[1])
+
+		moniker := elems[edge(set, "moniker").InV]
+		teq(t, testdata+"deprecated/old:Legacy", moniker.Identifier)
+		teq(t, "export", moniker.Kind)
+
+		refs := edge(set, "textDocument/references").InV
+		var props []string
+		for _, el := range out[refs] {
+			props = append(props, el.Property)
+		}
+		teq(t, []string{"definitions", "references"}, props)
+	})
+	t.Run("Imported", func(t *testing.T) {
+		// io.Closer within tags/tags.go
+		set := edge(rangeAt("tags/tags.go", 13, 5), "next").InV
+		moniker := elems[edge(set, "moniker").InV]
+		teq(t, "io:Closer", moniker.Identifier)
+		teq(t, "import", moniker.Kind)
+		for _, el := range out[set] {
+			teq(t, false, el.Label == "textDocument/definition")
+		}
+	})
+	t.Run("UTF16", func(t *testing.T) {
+		// Größe after a comment with two and three byte runes
+		r := elems[rangeAt("lsif/lsif.go", 12, 21)]
+		teq(t, 25, r.End.Character)
+		teq(t, "utf-16", first[0].PositionEncoding)
+	})
+	t.Run("ImportedKeys", func(t *testing.T) {
+		// strings.ToUpper, strings.ToLower, strings.Builder.Len and
+		// token.Position.Line each have their own result set
+		sets := make(map[int]bool)
+		var hovers []string
+		for _, pos := range [][2]int{{15, 28}, {15, 45}, {19, 30}, {20, 26}} {
+			set := edge(rangeAt("lsif/lsif.go", pos[0], pos[1]), "next").InV
+			sets[set] = true
+			hover := elems[edge(set, "textDocument/hover").InV]
+			hovers = append(hovers, hover.Result.Contents[0].(map[string]interface{})["value"].(string))
+		}
+		teq(t, 4, len(sets))
+		teq(t, []string{
+			"func strings.ToUpper(s string) string",
+			"func strings.ToLower(s string) string",
+			"func (*strings.Builder).Len() int",
+			"field Line int",
+		}, hovers)
+	})
+	t.Run("Implementations", func(t *testing.T) {
+		// type Reader interface within tags/tags.go
+		set := edge(rangeAt("tags/tags.go", 12, 6), "next").InV
+		impl := edge(set, "textDocument/implementation").InV
+		teq(t, []int{rangeAt("tags/tags.go", 18, 6)}, edge(impl, "item").InVs)
+
+		// Close method of *File implements io.Closer.Close
+		set = edge(rangeAt("tags/tags.go", 24, 16), "next").InV
+		teq(t, "tags:File.Close", strings.TrimPrefix(
+			elems[edge(set, "moniker").InV].Identifier, testdata))
+	})
+}
@@ -0,0 +1,260 @@
+package srcutil
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/doc"
+	"go/doc/comment"
+	"go/printer"
+	"go/token"
+	"io"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Markdown returns the documentation of this package rendered as GitHub
+// flavored Markdown. It contains the package overview, an index and then the
+// consts, vars, funcs and types of the package along with their examples.
+// Each declaration has an anchor named after its identifier, I.E. "#Reader"
+// and "#Reader.Read", which doc links such as [Reader] within comments will
+// link to. Doc links to other packages link to pkg.go.dev.
+func (d *Docs) Markdown() string {
+	var buf bytes.Buffer
+	d.WriteMarkdown(&buf)
+	return buf.String()
+}
+
+// WriteMarkdown is like Markdown except the output is written to w.
+func (d *Docs) WriteMarkdown(w io.Writer) error {
+	m := newMarkdown(d)
+	m.render()
+	_, err := w.Write(m.buf.Bytes())
+	return err
+}
+
+type markdown struct {
+	buf      bytes.Buffer
+	pkg      *Package
+	docPkg   *doc.Package
+	fileSet  *token.FileSet
+	printer  *comment.Printer
+	examples map[string][]Example
+}
+
+func newMarkdown(d *Docs) *markdown {
+	p := d.Package
+	m := &markdown{
+		pkg:      p,
+		docPkg:   p.tc.docPkg,
+		fileSet:  p.tc.docFileSet,
+		examples: p.exampleIndex(),
+	}
+	m.printer = m.docPkg.Printer()
+	m.printer.HeadingLevel = 3
+	m.printer.DocLinkURL = func(link *comment.DocLink) string {
+		return docLinkURL(p.ImportPath, link)
+	}
+	return m
+}
+
+// docLinkURL returns an anchor within the current page for doc links to the
+// package with importPath and a pkg.go.dev URL for all other packages.
+func docLinkURL(importPath string, link *comment.DocLink) string {
+	name := docLinkName(link)
+	if len(link.ImportPath) == 0 || link.ImportPath == importPath {
+		return "#" + name
+	}
+	url := "https://pkg.go.dev/" + link.ImportPath
+	if len(name) > 0 {
+		url += "#" + name
+	}
+	return url
+}
+
+// docLinkName returns the anchor name of a doc link, I.E. "Reader.Read".
+func docLinkName(link *comment.DocLink) string {
+	if len(link.Recv) > 0 {
+		return link.Recv + "." + link.Name
+	}
+	return link.Name
+}
+
+// docFuncs returns funcs without the Test, Benchmark, Fuzz and Example funcs
+// declared within the _test.go files of the package.
+func docFuncs(funcs []*doc.Func) []*doc.Func {
+	var out []*doc.Func
+	for _, f := range funcs {
+		if isTest(f.Name, "Test") || isTest(f.Name, "Benchmark") ||
+			isTest(f.Name, "Fuzz") || isTest(f.Name, "Example") {
+			continue
+		}
+		out = append(out, f)
+	}
+	return out
+}
+
+// exampleTarget splits the name of an example, without the "Example" prefix,
+// into the identifier it documents and its suffix as described by the testing
+// package, I.E. "T_M_suffix" returns "T.M" and "suffix".
+func exampleTarget(name string) (id, suffix string) {
+	if i := strings.LastIndex(name, "_"); i >= 0 {
+		r, _ := utf8.DecodeRuneInString(name[i+1:])
+		if unicode.IsLower(r) {
+			name, suffix = name[:i], name[i+1:]
+		}
+	}
+	return strings.Replace(name, "_", ".", 1), suffix
+}
+
+func (m *markdown) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&m.buf, format, args...)
+}
+
+func (m *markdown) comment(text string) {
+	if len(text) > 0 {
+		m.buf.Write(m.printer.Markdown(m.docPkg.Parser().Parse(text)))
+		m.buf.WriteByte('\n')
+	}
+}
+
+func (m *markdown) code(node interface{}, fileSet *token.FileSet) {
+	m.printf("```go\n%s\n```\n\n", nodeString(fileSet, node))
+}
+
+func (m *markdown) render() {
+	m.printf("# Package %s\n\n", m.docPkg.Name)
+	m.printf("`import \"%s\"`\n\n", m.pkg.ImportPath)
+	m.comment(m.docPkg.Doc)
+	m.renderExamples(``)
+	m.renderIndex()
+
+	if len(m.docPkg.Consts) > 0 {
+		m.printf("## <a id=\"pkg-constants\"></a>Constants\n\n")
+		m.renderValues(m.docPkg.Consts)
+	}
+	if len(m.docPkg.Vars) > 0 {
+		m.printf("## <a id=\"pkg-variables\"></a>Variables\n\n")
+		m.renderValues(m.docPkg.Vars)
+	}
+	if funcs := docFuncs(m.docPkg.Funcs); len(funcs) > 0 {
+		m.printf("## <a id=\"pkg-functions\"></a>Functions\n\n")
+		for _, f := range funcs {
+			m.renderFunc("###", ``, f)
+		}
+	}
+	if len(m.docPkg.Types) > 0 {
+		m.printf("## <a id=\"pkg-types\"></a>Types\n\n")
+		for _, typ := range m.docPkg.Types {
+			m.renderType(typ)
+		}
+	}
+}
+
+func (m *markdown) renderIndex() {
+	m.printf("## <a id=\"pkg-index\"></a>Index\n\n")
+	if len(m.docPkg.Consts) > 0 {
+		m.printf("- [Constants](#pkg-constants)\n")
+	}
+	if len(m.docPkg.Vars) > 0 {
+		m.printf("- [Variables](#pkg-variables)\n")
+	}
+	for _, f := range docFuncs(m.docPkg.Funcs) {
+		m.printf("- [%s](#%s)\n", m.funcSignature(f), f.Name)
+	}
+	for _, typ := range m.docPkg.Types {
+		m.printf("- [type %s](#%s)\n", typ.Name, typ.Name)
+		for _, f := range typ.Funcs {
+			m.printf("  - [%s](#%s)\n", m.funcSignature(f), f.Name)
+		}
+		for _, f := range typ.Methods {
+			m.printf("  - [%s](#%s.%s)\n", m.funcSignature(f), typ.Name, f.Name)
+		}
+	}
+	m.printf("\n")
+}
+
+func (m *markdown) renderValues(values []*doc.Value) {
+	for _, v := range values {
+		m.code(v.Decl, m.fileSet)
+		m.comment(v.Doc)
+	}
+}
+
+func (m *markdown) renderFunc(heading, recv string, f *doc.Func) {
+	id := f.Name
+	if len(recv) > 0 {
+		id = recv + "." + f.Name
+	}
+	m.printf("%s <a id=\"%s\"></a>%s\n\n", heading, id, m.funcSignature(f))
+	m.code(m.funcDecl(f), m.fileSet)
+	m.comment(f.Doc)
+	m.renderExamples(id)
+}
+
+func (m *markdown) renderType(typ *doc.Type) {
+	m.printf("### <a id=\"%s\"></a>type %s\n\n", typ.Name, typ.Name)
+	m.code(typ.Decl, m.fileSet)
+	m.comment(typ.Doc)
+	m.renderExamples(typ.Name)
+	m.renderValues(typ.Consts)
+	m.renderValues(typ.Vars)
+	for _, f := range typ.Funcs {
+		m.renderFunc("####", ``, f)
+	}
+	for _, f := range typ.Methods {
+		m.renderFunc("####", typ.Name, f)
+	}
+}
+
+func (m *markdown) renderExamples(id string) {
+	for _, ex := range m.examples[id] {
+		title := "Example"
+		if len(ex.Suffix) > 0 {
+			title += " (" + ex.Suffix + ")"
+		}
+		m.printf("<details><summary>%s</summary>\n\n", title)
+		m.comment(ex.Doc)
+		m.printf("```go\n%s\n```\n\n", exampleCode(m.pkg.tc.fileSet, ex.Example))
+		if len(ex.Output) > 0 {
+			m.printf("Output:\n\n```\n%s```\n\n", ex.Output)
+		}
+		m.printf("</details>\n\n")
+	}
+}
+
+// funcDecl returns a copy of the declaration of f without its body or doc.
+func (m *markdown) funcDecl(f *doc.Func) *ast.FuncDecl {
+	decl := *f.Decl
+	decl.Body, decl.Doc = nil, nil
+	return &decl
+}
+
+func (m *markdown) funcSignature(f *doc.Func) string {
+	return nodeString(m.fileSet, m.funcDecl(f))
+}
+
+// exampleCode returns the source of an example, the enclosing braces of the
+// example func body are removed like go doc does.
+func exampleCode(fileSet *token.FileSet, ex doc.Example) string {
+	code := nodeString(fileSet, &printer.CommentedNode{Node: ex.Code, Comments: ex.Comments})
+	if _, ok := ex.Code.(*ast.BlockStmt); !ok {
+		return code
+	}
+	code = strings.TrimSuffix(strings.TrimPrefix(code, "{\n"), "\n}")
+	lines := strings.Split(code, "\n")
+	for i := range lines {
+		lines[i] = strings.TrimPrefix(lines[i], "\t")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func nodeString(fileSet *token.FileSet, node interface{}) string {
+	var buf bytes.Buffer
+	conf := printer.Config{Mode: printer.UseSpaces | printer.TabIndent, Tabwidth: 8}
+	if err := conf.Fprint(&buf, fileSet, node); err != nil {
+		return err.Error()
+	}
+	return buf.String()
+}
@@ -0,0 +1,68 @@
+package srcutil
+
+import (
+	"bytes"
+	"go/doc/comment"
+	"strings"
+	"testing"
+)
+
+func TestMarkdown(t *testing.T) {
+	ctx := FromWorkDir()
+	pkg, err := ctx.Import(tPkg.ImportPath)
+	tmust(t, err)
+	docs := pkg.Docs()
+	got := docs.Markdown()
+
+	t.Run("WriteMarkdown", func(t *testing.T) {
+		var buf bytes.Buffer
+		tmust(t, docs.WriteMarkdown(&buf))
+		teq(t, got, buf.String())
+	})
+	t.Run("Contents", func(t *testing.T) {
+		for _, exp := range []string{
+			"# Package tpkg\n\n`import \"" + tPkg.ImportPath + "\"`\n\nPackage tpkg is used",
+			"- [Constants](#pkg-constants)\n",
+			"- [func StringFunc(str string) string](#StringFunc)\n",
+			"- [type PublicStruct](#PublicStruct)\n",
+			"  - [func (p PublicStruct) MethodOne()](#PublicStruct.MethodOne)\n",
+			"### <a id=\"StringFunc\"></a>func StringFunc(str string) string\n\n" +
+				"```go\nfunc StringFunc(str string) string\n```\n\nStringFunc occaecati",
+			"#### <a id=\"PublicStruct.MethodOne\"></a>func (p PublicStruct) MethodOne()\n",
+			"```go\nfmt.Println(\"ExamplePublicStruct_MethodOne\")\n\n// Output:\n",
+			"Output:\n\n```\nExamplePublicStruct_MethodOne\n```\n",
+			"```go\nfmt.Println(\"Example\")\n\n// Output:\n",
+		} {
+			if !strings.Contains(got, exp) {
+				t.Errorf("exp Markdown to contain:\n%v\ngot:\n%v", exp, got)
+			}
+		}
+		if strings.Contains(got, "privateStruct") {
+			t.Errorf("exp unexported identifiers to be excluded")
+		}
+		if strings.Contains(got, "TestFuncOne") {
+			t.Errorf("exp test funcs to be excluded")
+		}
+	})
+	t.Run("DocLinks", func(t *testing.T) {
+		teq(t, "#Reader", docLinkURL("io", &comment.DocLink{ImportPath: "io", Name: "Reader"}))
+		teq(t, "#Reader.Read", docLinkURL("io", &comment.DocLink{Recv: "Reader", Name: "Read"}))
+		teq(t, "https://pkg.go.dev/io#Reader",
+			docLinkURL("bufio", &comment.DocLink{ImportPath: "io", Name: "Reader"}))
+		teq(t, "https://pkg.go.dev/io",
+			docLinkURL("bufio", &comment.DocLink{ImportPath: "io"}))
+	})
+	t.Run("ExampleTarget", func(t *testing.T) {
+		for name, exp := range map[string][2]string{
+			"":              {"", ""},
+			"_suffix":       {"", "suffix"},
+			"Func":          {"Func", ""},
+			"T_Method":      {"T.Method", ""},
+			"T_Method_more": {"T.Method", "more"},
+			"T_suffix":      {"T", "suffix"},
+		} {
+			id, suffix := exampleTarget(name)
+			teq(t, exp, [2]string{id, suffix})
+		}
+	})
+}
@@ -0,0 +1,294 @@
+package srcutil
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// GenerateMock returns the gofmt'd source of a file within the package with
+// the given import path and name, declaring a mock for each of ifaces. Types
+// of the destination package are unqualified while all others are qualified
+// and imported by their package name.
+//
+// The mock of an interface named Store is a struct named StoreMock with a
+// func field for each method, I.E. GetFunc for Get, which is called by the
+// method when set. Otherwise the method returns zero values. Each call is
+// recorded with its arguments, which are returned by a method named after the
+// method, I.E. GetCalls returns []StoreMockGetCall. The Calls and AssertCalls
+// methods return and check the number of calls to a method by name. Mocks are
+// safe for concurrent use.
+//
+// When a generated name collides with a method of the interface or another
+// generated name the lowest number which makes it unique is appended, I.E.
+// Calls2 for an interface with a Calls method. Parameters are renamed the same
+// way when they collide with the identifiers used by the mocked method.
+func GenerateMock(dstPath, dstName string, ifaces ...MethodSet) ([]byte, error) {
+	g := &mockGen{imports: NewImports(dstPath)}
+	g.imports.Add("sync", "sync")
+	fmt.Fprintf(&g.body, "\n")
+	for _, iface := range ifaces {
+		if err := g.mock(iface); err != nil {
+			return nil, err
+		}
+	}
+
+	var src bytes.Buffer
+	fmt.Fprintf(&src, "// Code generated by srcutil. DO NOT EDIT.\n\npackage %s\n\n", dstName)
+	src.Write(g.imports.Decl())
+	src.Write(g.body.Bytes())
+	return format.Source(src.Bytes())
+}
+
+// GenerateMocks returns the source of a file within this package declaring a
+// mock for each exported interface of this package as described by
+// GenerateMock. Generic interfaces are skipped.
+func (p *Package) GenerateMocks() ([]byte, error) {
+	if err := p.init(); err != nil {
+		return nil, err
+	}
+	var ifaces []MethodSet
+	scope := p.tc.typesPkg.Scope()
+	for _, name := range scope.Names() {
+		obj, ok := scope.Lookup(name).(*types.TypeName)
+		if !ok || !obj.Exported() || !types.IsInterface(obj.Type()) {
+			continue
+		}
+		if isTestFile(p.tc.fileSet.Position(obj.Pos())) {
+			continue
+		}
+		if named, ok := obj.Type().(*types.Named); !ok || named.TypeParams().Len() > 0 {
+			continue
+		}
+		ms, err := p.MethodSet(name)
+		if err != nil {
+			return nil, err
+		}
+		ifaces = append(ifaces, ms)
+	}
+	return GenerateMock(p.ImportPath, p.Name, ifaces...)
+}
+
+// WriteMocks writes the result of GenerateMocks to a file named
+// "<package>_mock.go" within dir, or the directory of this package when dir is
+// empty, and returns its path.
+func (p *Package) WriteMocks(dir string) (string, error) {
+	src, err := p.GenerateMocks()
+	if err != nil {
+		return ``, err
+	}
+	if len(dir) == 0 {
+		dir = p.Dir
+	}
+	path := filepath.Join(dir, p.Name+"_mock.go")
+	return path, os.WriteFile(path, src, 0644)
+}
+
+type mockGen struct {
+	imports *Imports
+	body    bytes.Buffer
+}
+
+func (g *mockGen) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.body, format, args...)
+}
+
+// mockParam is a parameter or result of a mocked method.
+type mockParam struct {
+	name, field, typ string
+}
+
+// mockNames is the set of names used within a scope of a mock.
+type mockNames map[string]bool
+
+// unique adds and returns name, or name followed by the lowest number which
+// makes it unique when it is already used.
+func (n mockNames) unique(name string) string {
+	out := name
+	for i := 2; n[out]; i++ {
+		out = name + strconv.Itoa(i)
+	}
+	n[out] = true
+	return out
+}
+
+// mockIdents are the generated identifiers of a mock.
+type mockIdents struct {
+	mu, calls, callsMethod, assertCalls string
+
+	// funcs and callsOf are the func field and calls method of each method
+	funcs, callsOf map[string]string
+}
+
+func (g *mockGen) mock(iface MethodSet) error {
+	if iface.Obj == nil {
+		return fmt.Errorf("mock: MethodSet %q has no object", iface.Name)
+	}
+	named, ok := iface.Obj.Type().(*types.Named)
+	if !ok || !types.IsInterface(named) {
+		return fmt.Errorf("mock: %v is not a named interface", iface.Name)
+	}
+	if named.TypeParams().Len() > 0 {
+		return fmt.Errorf("mock: generic interface %v is not supported", iface.Name)
+	}
+	pkgPath := iface.Obj.Pkg().Path()
+	qual := g.imports.Qualifier()
+
+	mock := iface.Name + "Mock"
+	names := iface.Names()
+
+	// the methods of the interface are reserved before generated names
+	taken := make(mockNames)
+	for _, name := range names {
+		taken[name] = true
+	}
+	ids := mockIdents{
+		mu: taken.unique("mu"), calls: taken.unique("calls"),
+		callsMethod: taken.unique("Calls"), assertCalls: taken.unique("AssertCalls"),
+		funcs: make(map[string]string), callsOf: make(map[string]string),
+	}
+	for _, name := range names {
+		ids.funcs[name] = taken.unique(name + "Func")
+		ids.callsOf[name] = taken.unique(name + "Calls")
+	}
+
+	// the doc comment does not use the package of the interface, so it must
+	// not be imported for it
+	ifaceName := iface.Name
+	if pkgPath != g.imports.Path {
+		ifaceName = iface.Obj.Pkg().Name() + "." + ifaceName
+	}
+	g.printf("// %s is a mock of %s.\ntype %s struct {\n", mock, ifaceName, mock)
+	for _, name := range names {
+		m := iface.Methods[name]
+		if !m.Exported() && pkgPath != g.imports.Path {
+			return fmt.Errorf("mock: %v has unexported method %v which can not be implemented",
+				iface.Name, name)
+		}
+		g.printf("\t// %s is called by %s when it is not nil.\n", ids.funcs[name], name)
+		g.printf("\t%s %s\n\n", ids.funcs[name], types.TypeString(m.Signature, qual))
+	}
+	g.printf("\t%s sync.Mutex\n\t%s struct {\n", ids.mu, ids.calls)
+	for _, name := range names {
+		g.printf("\t\t%s []%s%sCall\n", name, mock, name)
+	}
+	g.printf("\t}\n}\n\n")
+
+	for _, name := range names {
+		g.method(mock, name, iface.Methods[name].Signature, qual, ids)
+	}
+
+	g.printf("// %s returns the number of calls to the named method.\n", ids.callsMethod)
+	g.printf("func (m *%s) %s(method string) int {\n", mock, ids.callsMethod)
+	g.printf("\tm.%s.Lock()\n\tdefer m.%s.Unlock()\n\tswitch method {\n", ids.mu, ids.mu)
+	for _, name := range names {
+		g.printf("\tcase %q:\n\t\treturn len(m.%s.%s)\n", name, ids.calls, name)
+	}
+	g.printf("\t}\n\tpanic(\"%s: unknown method \" + method)\n}\n\n", mock)
+
+	g.printf("// %s reports an error to t unless the named method was called n\n",
+		ids.assertCalls)
+	g.printf("// times, t is usually a *testing.T.\n")
+	g.printf("func (m *%s) %s(t interface {\n\tHelper()\n", mock, ids.assertCalls)
+	g.printf("\tErrorf(format string, args ...interface{})\n}, method string, n int) {\n")
+	g.printf("\tt.Helper()\n\tif got := m.%s(method); got != n {\n", ids.callsMethod)
+	g.printf("\t\tt.Errorf(\"%s.%%s: exp %%d calls; got %%d\", method, n, got)\n", mock)
+	g.printf("\t}\n}\n\n")
+	return nil
+}
+
+func (g *mockGen) method(
+	mock, name string, sig *types.Signature, qual types.Qualifier, ids mockIdents,
+) {
+	call := mock + name + "Call"
+	var params, results []mockParam
+	for i := 0; i < sig.Params().Len(); i++ {
+		v := sig.Params().At(i)
+		params = append(params, mockParam{name: v.Name(), typ: types.TypeString(v.Type(), qual)})
+		if sig.Variadic() && i == sig.Params().Len()-1 {
+			params[i].typ = "..." + types.TypeString(v.Type().(*types.Slice).Elem(), qual)
+		}
+	}
+	for i := 0; i < sig.Results().Len(); i++ {
+		v := sig.Results().At(i)
+		results = append(results, mockParam{
+			name: fmt.Sprintf("r%d", i), typ: types.TypeString(v.Type(), qual)})
+	}
+
+	// parameters may not shadow the identifiers used by the method body or
+	// the imported packages, and results follow the parameters
+	taken := mockNames{"m": true, "append": true, "nil": true, call: true}
+	for _, importPath := range g.imports.Paths() {
+		imported, _ := g.imports.Name(importPath)
+		taken[imported] = true
+	}
+	for i := range params {
+		if len(params[i].name) == 0 || params[i].name == "_" {
+			params[i].name = fmt.Sprintf("p%d", i)
+		}
+		params[i].name = taken.unique(params[i].name)
+		params[i].field = mockField(params[i].name)
+	}
+	for i := range results {
+		results[i].name = taken.unique(results[i].name)
+	}
+
+	join := func(s []mockParam, f func(p mockParam) string) string {
+		out := make([]string, len(s))
+		for i, p := range s {
+			out[i] = f(p)
+		}
+		return strings.Join(out, ", ")
+	}
+	g.printf("// %s is a recorded call of %s.%s.\n", call, mock, name)
+	if len(params) == 0 {
+		g.printf("type %s struct{}\n\n", call)
+	} else {
+		g.printf("type %s struct {\n", call)
+		for _, p := range params {
+			g.printf("\t%s %s\n", p.field, strings.Replace(p.typ, "...", "[]", 1))
+		}
+		g.printf("}\n\n")
+	}
+
+	g.printf("// %s calls %s if it is not nil, otherwise it returns zero values.\n",
+		name, ids.funcs[name])
+	g.printf("func (m *%s) %s(%s) (%s) {\n", mock, name,
+		join(params, func(p mockParam) string { return p.name + " " + p.typ }),
+		join(results, func(p mockParam) string { return p.name + " " + p.typ }))
+	g.printf("\tm.%s.Lock()\n\tm.%s.%s = append(m.%s.%s, %s{%s})\n\tm.%s.Unlock()\n",
+		ids.mu, ids.calls, name, ids.calls, name, call, join(params, func(p mockParam) string {
+			return p.field + ": " + p.name
+		}), ids.mu)
+	args := join(params, func(p mockParam) string {
+		if strings.HasPrefix(p.typ, "...") {
+			return p.name + "..."
+		}
+		return p.name
+	})
+	fn := ids.funcs[name]
+	if len(results) == 0 {
+		g.printf("\tif m.%s != nil {\n\t\tm.%s(%s)\n\t}\n}\n\n", fn, fn, args)
+	} else {
+		g.printf("\tif m.%s != nil {\n\t\treturn m.%s(%s)\n\t}\n\treturn\n}\n\n",
+			fn, fn, args)
+	}
+
+	g.printf("// %s returns the recorded calls of %s.\n", ids.callsOf[name], name)
+	g.printf("func (m *%s) %s() []%s {\n", mock, ids.callsOf[name], call)
+	g.printf("\tm.%s.Lock()\n\tdefer m.%s.Unlock()\n", ids.mu, ids.mu)
+	g.printf("\treturn append([]%s(nil), m.%s.%s...)\n}\n\n", call, ids.calls, name)
+}
+
+// mockField returns the exported field name of a parameter name.
+func mockField(name string) string {
+	r, size := utf8.DecodeRuneInString(name)
+	return string(unicode.ToUpper(r)) + name[size:]
+}
@@ -0,0 +1,133 @@
+package srcutil
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateMock(t *testing.T) {
+	const stub = "github.com/cstockton/go-srcutil/testdata/stub"
+	ctx := FromWorkDir()
+	pkg, err := ctx.Import(stub)
+	tmust(t, err)
+	ms, err := pkg.MethodSet("Store")
+	tmust(t, err)
+
+	// check type checks src along with the files of the stub package when
+	// within is true, otherwise on its own.
+	check := func(t *testing.T, src []byte, within bool) {
+		fset := token.NewFileSet()
+		f, err := parser.ParseFile(fset, "mock.go", src, 0)
+		tmust(t, err)
+		files := []*ast.File{f}
+		if within {
+			pkgFiles := pkg.Files()
+			for _, path := range pkgFiles.SourcePaths() {
+				f, err := parser.ParseFile(fset, path, nil, 0)
+				tmust(t, err)
+				files = append(files, f)
+			}
+		}
+		conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+		_, err = conf.Check(f.Name.Name, fset, files, nil)
+		tmust(t, err)
+	}
+
+	t.Run("GenerateMock", func(t *testing.T) {
+		src, err := GenerateMock("example.com/mocks", "mocks", ms)
+		tmust(t, err)
+		check(t, src, false)
+
+		got := string(src)
+		for _, exp := range []string{
+			"// Code generated by srcutil. DO NOT EDIT.\n\npackage mocks\n",
+			"\t\"" + stub + "\"\n",
+			"// StoreMock is a mock of stub.Store.\ntype StoreMock struct {\n",
+			"\tGetFunc func(ctx context.Context, key string) (*stub.Item, error)\n",
+			"type StoreMockPutCall struct {\n\tCtx   context.Context\n\tItems []*stub.Item\n}\n",
+			"type StoreMockLenCall struct{}\n",
+			"\t\treturn m.PutFunc(ctx, items...)\n",
+			"func (m *StoreMock) Walk(p0 func(*stub.Item) bool) {\n",
+			"func (m *StoreMock) GetCalls() []StoreMockGetCall {\n",
+			"func (m *StoreMock) Calls(method string) int {\n",
+		} {
+			if !strings.Contains(got, exp) {
+				t.Errorf("exp mock to contain %q; got:\n%s", exp, got)
+			}
+		}
+	})
+	t.Run("GenerateMocks", func(t *testing.T) {
+		src, err := pkg.GenerateMocks()
+		tmust(t, err)
+		check(t, src, true)
+
+		got := string(src)
+		teq(t, true, strings.Contains(got, "package stub\n"))
+		teq(t, true, strings.Contains(got, "type StoreMock struct {\n"))
+		teq(t, true, strings.Contains(got, "type PrivateMock struct {\n"))
+		teq(t, true, strings.Contains(got, "\tgetFunc func() string\n"))
+		teq(t, false, strings.Contains(got, "GenericMock"))
+		teq(t, false, strings.Contains(got, stub))
+	})
+	t.Run("WriteMocks", func(t *testing.T) {
+		dir := t.TempDir()
+		path, err := pkg.WriteMocks(dir)
+		tmust(t, err)
+		teq(t, filepath.Join(dir, "stub_mock.go"), path)
+
+		src, err := os.ReadFile(path)
+		tmust(t, err)
+		exp, err := pkg.GenerateMocks()
+		tmust(t, err)
+		teq(t, string(exp), string(src))
+	})
+	t.Run("Failure", func(t *testing.T) {
+		for _, name := range []string{"Item", "Generic", "Private"} {
+			ms, err := pkg.MethodSet(name)
+			tmust(t, err)
+			if _, err = GenerateMock("example.com/mocks", "mocks", ms); err == nil {
+				t.Errorf("exp error for %v", name)
+			}
+		}
+	})
+}
+
+func TestGenerateMockCollisions(t *testing.T) {
+	pkg, err := FromWorkDir().Import("github.com/cstockton/go-srcutil/testdata/mock")
+	tmust(t, err)
+	ms, err := pkg.MethodSet("Collide")
+	tmust(t, err)
+
+	src, err := GenerateMock("example.com/mocks", "mocks", ms)
+	tmust(t, err)
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "mock.go", src, 0)
+	tmust(t, err)
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	_, err = conf.Check(f.Name.Name, fset, []*ast.File{f}, nil)
+	tmust(t, err)
+
+	got := string(src)
+	for _, exp := range []string{
+		"func (m *CollideMock) Calls() (r0 int) {\n",
+		"func (m *CollideMock) Calls2(method string) int {\n",
+		"func (m *CollideMock) AssertCalls2(t interface {\n",
+		"\t\tt.Errorf(\"CollideMock.%s: exp %d calls; got %d\", method, n, got)\n",
+		"func (m *CollideMock) Get(m2 string, r0 string, sync2 sync.Locker, append2 int) (r02 string, r1 error) {\n",
+		"\tGetFunc2 func(m string, r0 string, sync sync.Locker, append int) (string, error)\n",
+		"func (m *CollideMock) GetCalls2() []CollideMockGetCall {\n",
+		"func (m *CollideMock) GetCallsCalls() []CollideMockGetCallsCall {\n",
+		"func (m *CollideMock) GetFunc() {\n\tm.mu.Lock()\n",
+	} {
+		if !strings.Contains(got, exp) {
+			t.Errorf("exp mock to contain %q; got:\n%s", exp, got)
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package srcutil
+
+import (
+	"go/ast"
+	"go/token"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultNoteMarkers are the markers of notes without a uid that are found
+// when NoteFilter.Bare is set and NoteFilter.Markers is empty.
+var DefaultNoteMarkers = []string{"BUG", "FIXME", "HACK", "TODO", "XXX"}
+
+// Note is a marked comment such as "TODO(uid): body" found anywhere within the
+// source of a package, including comments within func bodies.
+type Note struct {
+	ImportPath string
+	Marker     string
+
+	// UID is the value within the parentheses that follow the marker, it is
+	// usually the author or an issue number. It is empty for bare notes.
+	UID  string
+	Body string
+	Pos  token.Position
+
+	// Decl is the name of the top level declaration the note is within or
+	// documents, methods are named "Type.Method". It is empty for notes at
+	// file scope.
+	Decl string
+}
+
+// NoteFilter selects the notes returned by Package.Notes and CollectNotes.
+type NoteFilter struct {
+	// Markers and UIDs select notes having one of the given values, when empty
+	// notes with any marker or uid are selected.
+	Markers []string
+	UIDs    []string
+
+	// Bare also selects notes without a uid such as "// TODO: body". Unlike
+	// notes with a uid the marker must be followed by a colon and be one of
+	// Markers, or one of DefaultNoteMarkers when Markers is empty.
+	Bare bool
+}
+
+func (f NoteFilter) match(n Note) bool {
+	if len(f.Markers) > 0 && !hasString(f.Markers, n.Marker) {
+		return false
+	}
+	if len(f.UIDs) > 0 && !hasString(f.UIDs, n.UID) {
+		return false
+	}
+	if len(n.UID) == 0 {
+		markers := f.Markers
+		if len(markers) == 0 {
+			markers = DefaultNoteMarkers
+		}
+		return f.Bare && hasString(markers, n.Marker)
+	}
+	return true
+}
+
+// Notes is a slice of Note.
+type Notes []Note
+
+// ByMarker returns the notes grouped by their marker.
+func (s Notes) ByMarker() map[string]Notes {
+	return s.group(func(n Note) string { return n.Marker })
+}
+
+// ByUID returns the notes grouped by their uid, bare notes are grouped under
+// the empty string.
+func (s Notes) ByUID() map[string]Notes {
+	return s.group(func(n Note) string { return n.UID })
+}
+
+// ByPackage returns the notes grouped by their import path.
+func (s Notes) ByPackage() map[string]Notes {
+	return s.group(func(n Note) string { return n.ImportPath })
+}
+
+func (s Notes) group(key func(n Note) string) map[string]Notes {
+	out := make(map[string]Notes)
+	for _, n := range s {
+		out[key(n)] = append(out[key(n)], n)
+	}
+	return out
+}
+
+// CollectNotes returns the notes matching filter within each of the given
+// packages, in the order of the packages and then by position. The first
+// error encountered is returned.
+func CollectNotes(pkgs []*Package, filter NoteFilter) (Notes, error) {
+	var out Notes
+	for _, pkg := range pkgs {
+		notes, err := pkg.Notes(filter)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, notes...)
+	}
+	return out, nil
+}
+
+// Notes returns the notes of this package matching filter, ordered by
+// position. Unlike Docs.Notes every comment is searched rather than only those
+// go/doc recognizes, and a note may begin on any line of a comment. A note
+// ends at the start of the next note or the end of the comment group. An
+// error is returned when the package could not be type checked.
+func (p *Package) Notes(filter NoteFilter) (Notes, error) {
+	if err := p.init(); err != nil {
+		return nil, err
+	}
+	var out Notes
+	for _, f := range p.astFiles(p.tc.astPkg) {
+		for _, group := range f.Comments {
+			for _, n := range p.groupNotes(group) {
+				n.Decl = enclosingDecl(f, group.Pos())
+				if filter.match(n) {
+					out = append(out, n)
+				}
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i].Pos, out[j].Pos
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		return a.Offset < b.Offset
+	})
+	return out, nil
+}
+
+var noteRx = regexp.MustCompile(`^[ \t]*([A-Z][A-Z]+)(?:\(([^)]+)\):?|:)(.*)$`)
+
+// groupNotes returns every note within a comment group, including bare notes
+// which are later discarded by the NoteFilter unless requested.
+func (p *Package) groupNotes(group *ast.CommentGroup) []Note {
+	var (
+		out  []Note
+		cur  *Note
+		body []string
+	)
+	flush := func() {
+		if cur != nil {
+			cur.Body = strings.TrimSpace(strings.Join(body, "\n"))
+			if len(cur.Body) > 0 {
+				out = append(out, *cur)
+			}
+		}
+		cur, body = nil, nil
+	}
+	for _, c := range group.List {
+		for _, line := range commentLines(c) {
+			if m := noteRx.FindStringSubmatch(line.text); m != nil {
+				flush()
+				cur = &Note{
+					ImportPath: p.ImportPath, Marker: m[1], UID: m[2],
+					Pos: p.tc.fileSet.Position(line.pos)}
+				body = append(body, strings.TrimSpace(m[3]))
+				continue
+			}
+			if cur != nil {
+				body = append(body, strings.TrimSpace(line.text))
+			}
+		}
+	}
+	flush()
+	return out
+}
+
+type commentLine struct {
+	pos  token.Pos
+	text string
+}
+
+// commentLines returns the lines of a comment without the comment markers,
+// each with the position of the start of the line.
+func commentLines(c *ast.Comment) []commentLine {
+	if strings.HasPrefix(c.Text, "//") {
+		return []commentLine{{c.Slash, c.Text[2:]}}
+	}
+	var out []commentLine
+	text, off := strings.TrimSuffix(c.Text[2:], "*/"), 2
+	for _, line := range strings.Split(text, "\n") {
+		trimmed := strings.TrimLeft(line, " \t")
+		trimmed = strings.TrimPrefix(trimmed, "*")
+		out = append(out, commentLine{c.Slash + token.Pos(off), trimmed})
+		off += len(line) + 1
+	}
+	return out
+}
+
+// enclosingDecl returns the name of the top level declaration in f which
+// contains pos or is documented by a comment containing pos.
+func enclosingDecl(f *ast.File, pos token.Pos) string {
+	within := func(doc *ast.CommentGroup, node ast.Node) bool {
+		start := node.Pos()
+		if doc != nil {
+			start = doc.Pos()
+		}
+		return start <= pos && pos < node.End()
+	}
+	for _, decl := range f.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			if !within(decl.Doc, decl) {
+				continue
+			}
+			if decl.Recv != nil && len(decl.Recv.List) > 0 {
+				if recv := recvTypeName(decl.Recv.List[0].Type); len(recv) > 0 {
+					return recv + "." + decl.Name.Name
+				}
+			}
+			return decl.Name.Name
+		case *ast.GenDecl:
+			if !within(decl.Doc, decl) || len(decl.Specs) == 0 {
+				continue
+			}
+			for _, spec := range decl.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					if within(spec.Doc, spec) || len(decl.Specs) == 1 {
+						return spec.Name.Name
+					}
+				case *ast.ValueSpec:
+					if within(spec.Doc, spec) || len(decl.Specs) == 1 {
+						return spec.Names[0].Name
+					}
+				}
+			}
+		}
+	}
+	return ``
+}
+
+// recvTypeName returns the name of the type of a method receiver expression
+// such as "*T" or "T[K, V]".
+func recvTypeName(expr ast.Expr) string {
+	for {
+		switch x := expr.(type) {
+		case *ast.Ident:
+			return x.Name
+		case *ast.StarExpr:
+			expr = x.X
+		case *ast.ParenExpr:
+			expr = x.X
+		case *ast.IndexExpr:
+			expr = x.X
+		case *ast.IndexListExpr:
+			expr = x.X
+		default:
+			return ``
+		}
+	}
+}
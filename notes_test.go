@@ -0,0 +1,76 @@
+package srcutil
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestNotes(t *testing.T) {
+	const notes = "github.com/cstockton/go-srcutil/testdata/notes"
+	ctx := FromWorkDir()
+	pkg, err := ctx.Import(notes)
+	tmust(t, err)
+
+	format := func(s Notes) (out []string) {
+		for _, n := range s {
+			out = append(out, fmt.Sprintf("%s:%d %s(%s) %s: %q",
+				filepath.Base(n.Pos.Filename), n.Pos.Line, n.Marker, n.UID, n.Decl, n.Body))
+		}
+		return
+	}
+	notesOf := func(t *testing.T, filter NoteFilter) Notes {
+		got, err := pkg.Notes(filter)
+		tmust(t, err)
+		return got
+	}
+
+	t.Run("Default", func(t *testing.T) {
+		teq(t, []string{
+			`notes.go:4 TODO(cstockton) : "Package level note\nspanning two lines."`,
+			`notes.go:6 BUG(chris) : "Second note in the same group."`,
+			`notes.go:10 FIXME(chris) Store: "Store is not safe for concurrent use."`,
+			`notes.go:24 HACK(cstockton) Store.Put: "block comment note."`,
+		}, format(notesOf(t, NoteFilter{})))
+	})
+	t.Run("Bare", func(t *testing.T) {
+		got := notesOf(t, NoteFilter{Bare: true})
+		teq(t, 6, len(got))
+		teq(t, []string{
+			`notes.go:17 TODO() Store.Get: "handle missing keys."`,
+			`notes.go:33 XXX() A: "bare note on a grouped const."`,
+		}, format(got.ByUID()[``]))
+		teq(t, notes, got[0].ImportPath)
+
+		got = notesOf(t, NoteFilter{Markers: []string{"NOTE"}, Bare: true})
+		teq(t, []string{
+			`notes.go:27 NOTE() Store.Put: "this is not a default marker.\nTODO(): empty uid is not a note."`,
+		}, format(got))
+	})
+	t.Run("Filter", func(t *testing.T) {
+		got := notesOf(t, NoteFilter{UIDs: []string{"chris"}})
+		teq(t, []string{"BUG", "FIXME"}, []string{got[0].Marker, got[1].Marker})
+
+		got = notesOf(t, NoteFilter{Markers: []string{"TODO"}, UIDs: []string{"chris"}})
+		teq(t, 0, len(got))
+
+		got = notesOf(t, NoteFilter{Markers: []string{"TODO"}, Bare: true})
+		teq(t, 2, len(got))
+	})
+	t.Run("CollectNotes", func(t *testing.T) {
+		tpkg, err := ctx.Import(tPkg.ImportPath)
+		tmust(t, err)
+		got, err := CollectNotes([]*Package{pkg, tpkg}, NoteFilter{})
+		tmust(t, err)
+		teq(t, 8, len(got))
+
+		byPkg := got.ByPackage()
+		teq(t, 4, len(byPkg[notes]))
+		teq(t, 4, len(byPkg[tPkg.ImportPath]))
+
+		byMarker := got.ByMarker()
+		teq(t, 2, len(byMarker["HELLO"]))
+		teq(t, 2, len(byMarker["WORLD"]))
+		teq(t, 1, len(byMarker["TODO"]))
+	})
+}
@@ -29,6 +29,7 @@ type Package struct {
 	build.Package
 	once sync.Once
 	tc   *toolchain
+	err  error
 }
 
 // Import is shorthand for FromWorkDir().Import("pkgname").
@@ -44,11 +45,13 @@ func Import(pkgName string) (*Package, error) {
 }
 
 type toolchain struct {
-	fileSet   *token.FileSet
-	astPkg    *ast.Package
-	docPkg    *doc.Package
-	typesPkg  *types.Package
-	typesInfo *types.Info
+	fileSet    *token.FileSet
+	astPkg     *ast.Package
+	xtestPkg   *ast.Package // the package_test package, may be nil
+	docFileSet *token.FileSet
+	docPkg     *doc.Package
+	typesPkg   *types.Package
+	typesInfo  *types.Info
 }
 
 // Synopsis implements fmt.Stringer.
@@ -98,6 +101,8 @@ func (p *Package) typesInfo() *types.Info {
 
 // ToTypes provides access to a *types.Package. A new *types.Package will be
 // created each call and a nil pointer will be returned when error is non-nil.
+// The path of the *types.Package is the ImportPath of this package, so its
+// types are written qualified by import path, I.E. "github.com/user/pkg.T".
 func (p *Package) ToTypes() (*types.Package, error) {
 	tc, err := p.toToolchain(nil)
 	if err != nil {
@@ -116,6 +121,29 @@ func (p *Package) ToInfo() (*types.Info, *types.Package, error) {
 	return tc.typesInfo, tc.typesPkg, nil
 }
 
+// TypedFiles are the files of a package and the types checked from them, all
+// created from the same parse.
+type TypedFiles struct {
+	FileSet *token.FileSet
+	Files   []*ast.File // ordered by file name
+	Info    *types.Info
+	Types   *types.Package
+}
+
+// ToFiles is like ToInfo but also returns the *token.FileSet and the files the
+// types were checked from, for tools needing all of them to be from the same
+// parse. A nil pointer will be returned when error is non-nil.
+func (p *Package) ToFiles() (*TypedFiles, error) {
+	tc, err := p.toToolchain(p.typesInfo())
+	if err != nil {
+		return nil, err
+	}
+	files := p.astFiles(tc.astPkg)
+	sort.Slice(files, func(i, j int) bool { return files[i].Pos() < files[j].Pos() })
+	return &TypedFiles{FileSet: tc.fileSet, Files: files,
+		Info: tc.typesInfo, Types: tc.typesPkg}, nil
+}
+
 // Docs groups the documentation related methods.
 type Docs struct {
 	Package *Package
@@ -209,10 +237,166 @@ func (d *Docs) Funcs() []doc.Func {
 	return out
 }
 
+// docIndex returns the doc comments of each exported declaration keyed by
+// name, methods and fields are keyed by "Type.Name".
+func (p *Package) docIndex() map[string]string {
+	idx := make(map[string]string)
+	d := Docs{p}
+	for _, decl := range d.allDecls() {
+		if decl.Kind != "package" {
+			idx[decl.Name] = decl.Doc
+		}
+	}
+	return idx
+}
+
+// docDecl is a single exported declaration and its doc comment, as found by
+// Docs.decls. Methods and fields are named "Type.Name".
+type docDecl struct {
+	Name string
+	Kind string
+	Doc  string
+
+	// Pos is the position of the declared identifier, while DocPos is where
+	// problems with Doc are reported. It is the position of the comment when
+	// go/doc leaves it within the AST, otherwise the position of the enclosing
+	// declaration. Grouped consts and vars may share a single DocPos.
+	Pos    token.Position
+	DocPos token.Position
+
+	// Group is true when Doc belongs to a group of declarations rather than
+	// this one, or when this is a struct field or interface method.
+	Group bool
+}
+
+// decls returns the package comment followed by each exported declaration in
+// the go/doc package order, excluding those declared within _test.go files.
+func (d *Docs) decls() []docDecl {
+	var out []docDecl
+	for _, decl := range d.allDecls() {
+		if decl.Kind == "package" || !isTestFile(decl.Pos) {
+			out = append(out, decl)
+		}
+	}
+	return out
+}
+
+// allDecls is like decls but includes the declarations of _test.go files.
+func (d *Docs) allDecls() []docDecl {
+	p := d.Package
+	docPkg, fileSet := p.tc.docPkg, p.tc.docFileSet
+	pos := func(node ast.Node) token.Position {
+		return fileSet.Position(node.Pos())
+	}
+	docPos := func(group *ast.CommentGroup, node ast.Node) token.Position {
+		if group == nil {
+			return pos(node)
+		}
+		return pos(group)
+	}
+
+	var out []docDecl
+	pkgDecl := docDecl{Name: p.Name, Kind: "package", Doc: docPkg.Doc}
+	for _, name := range docPkg.Filenames {
+		if f := p.tc.astPkg.Files[name]; f != nil && f.Doc != nil && !isTestFile(
+			p.tc.fileSet.Position(f.Pos())) {
+			pkgDecl.Pos = p.tc.fileSet.Position(f.Name.Pos())
+			pkgDecl.DocPos = p.tc.fileSet.Position(f.Doc.Pos())
+			break
+		}
+	}
+	out = append(out, pkgDecl)
+
+	values := func(kind string, values []*doc.Value) {
+		for _, v := range values {
+			for _, spec := range v.Decl.Specs {
+				vs := spec.(*ast.ValueSpec)
+				for _, ident := range vs.Names {
+					if !ident.IsExported() {
+						continue
+					}
+					decl := docDecl{Name: ident.Name, Kind: kind, Doc: v.Doc,
+						Pos: pos(ident), DocPos: docPos(v.Decl.Doc, v.Decl),
+						Group: v.Decl.Lparen.IsValid() || len(vs.Names) > 1}
+					if vs.Doc != nil {
+						decl.Doc, decl.DocPos = vs.Doc.Text(), pos(vs.Doc)
+						decl.Group = len(vs.Names) > 1
+					}
+					out = append(out, decl)
+				}
+			}
+		}
+	}
+	funcs := func(recv string, funcs []*doc.Func) {
+		for _, f := range docFuncs(funcs) {
+			decl := docDecl{Name: f.Name, Kind: "func", Doc: f.Doc,
+				Pos: pos(f.Decl.Name), DocPos: docPos(f.Decl.Doc, f.Decl)}
+			if len(recv) > 0 {
+				decl.Name, decl.Kind = recv+"."+f.Name, "method"
+			}
+			out = append(out, decl)
+		}
+	}
+	fields := func(typ *doc.Type) {
+		for _, spec := range typ.Decl.Specs {
+			ts, ok := spec.(*ast.TypeSpec)
+			if !ok {
+				continue
+			}
+			kind, list := "field", (*ast.FieldList)(nil)
+			switch t := ts.Type.(type) {
+			case *ast.StructType:
+				list = t.Fields
+			case *ast.InterfaceType:
+				kind, list = "method", t.Methods
+			}
+			if list == nil {
+				continue
+			}
+			for _, field := range list.List {
+				group := field.Doc
+				if group == nil {
+					group = field.Comment
+				}
+				for _, ident := range field.Names {
+					if ident.IsExported() {
+						out = append(out, docDecl{Name: typ.Name + "." + ident.Name,
+							Kind: kind, Doc: group.Text(), Pos: pos(ident),
+							DocPos: docPos(group, ident), Group: true})
+					}
+				}
+			}
+		}
+	}
+
+	values("const", docPkg.Consts)
+	values("var", docPkg.Vars)
+	funcs(``, docPkg.Funcs)
+	for _, typ := range docPkg.Types {
+		var typePos ast.Node = typ.Decl
+		for _, spec := range typ.Decl.Specs {
+			if ts, ok := spec.(*ast.TypeSpec); ok && ts.Name.Name == typ.Name {
+				typePos = ts.Name
+			}
+		}
+		out = append(out, docDecl{Name: typ.Name, Kind: "type", Doc: typ.Doc,
+			Pos: pos(typePos), DocPos: docPos(typ.Decl.Doc, typ.Decl)})
+		fields(typ)
+		values("const", typ.Consts)
+		values("var", typ.Vars)
+		funcs(``, typ.Funcs)
+		funcs(typ.Name, typ.Methods)
+	}
+	return out
+}
+
 // Var represents a packages top level named variable.
 type Var struct {
 	*types.Var
 	Named *types.Named
+
+	// Doc is the doc comment of the variable when it was returned by a Package.
+	Doc string
 }
 
 // NewVar returns a Var, typeVar must not be nil.
@@ -223,6 +407,7 @@ func NewVar(typeVar *types.Var, typeNamed *types.Named) Var {
 // Vars returns all the packages named variables from the package scope.
 func (p *Package) Vars() []Var {
 	p.init()
+	docs := p.docIndex()
 	var vars []Var
 	scope := p.tc.typesPkg.Scope()
 	for _, name := range scope.Names() {
@@ -243,7 +428,9 @@ func (p *Package) Vars() []Var {
 		if !ok {
 			asNamed, _ = asVar.Type().(*types.Named)
 		}
-		vars = append(vars, NewVar(asVar, asNamed))
+		v := NewVar(asVar, asNamed)
+		v.Doc = docs[name]
+		vars = append(vars, v)
 	}
 	return vars
 }
@@ -252,6 +439,11 @@ func (p *Package) Vars() []Var {
 type Struct struct {
 	*types.Struct
 	Named *types.Named
+
+	// Doc and Examples are the doc comment and examples of the named type
+	// when it was returned by a Package.
+	Doc      string
+	Examples []Example
 }
 
 // NewStruct returns a Struct, typeStruct must not be nil.
@@ -262,6 +454,7 @@ func NewStruct(typeStruct *types.Struct, typeNamed *types.Named) Struct {
 // Structs returns all the packages named structs from the package scope.
 func (p *Package) Structs() []Struct {
 	p.init()
+	docs, examples := p.docIndex(), p.exampleIndex()
 	var structs []Struct
 	scope := p.tc.typesPkg.Scope()
 	for _, name := range scope.Names() {
@@ -281,7 +474,9 @@ func (p *Package) Structs() []Struct {
 		if !ok {
 			continue
 		}
-		structs = append(structs, NewStruct(asStruct, asNamed))
+		s := NewStruct(asStruct, asNamed)
+		s.Doc, s.Examples = docs[name], examples[name]
+		structs = append(structs, s)
 	}
 	return structs
 }
@@ -291,23 +486,41 @@ func (p *Package) Structs() []Struct {
 type Func struct {
 	*types.Func
 	*types.Signature
+
+	// Doc and Examples are the doc comment and examples of the func when it
+	// was returned by a Package.
+	Doc      string
+	Examples []Example
 }
 
-// String implements fmt.Stringer.
+// String implements fmt.Stringer. Objects of the package declaring f are
+// qualified by its package name, all others by their import path.
 func (f Func) String() string {
-	return f.Func.String()
+	return types.ObjectString(f.Func, func(pkg *types.Package) string {
+		if pkg == f.Func.Pkg() {
+			return pkg.Name()
+		}
+		return pkg.Path()
+	})
+}
+
+// Render returns the declaration of this func like String, with packages
+// qualified by q such as the Qualifier of an Imports.
+func (f Func) Render(q types.Qualifier) string {
+	return types.ObjectString(f.Func, q)
 }
 
 // NewFunc returns a Function, typeFunc must not be nil.
 func NewFunc(typeFunc *types.Func) Func {
 	// funcs always have signatures
-	return Func{typeFunc, typeFunc.Type().(*types.Signature)}
+	return Func{Func: typeFunc, Signature: typeFunc.Type().(*types.Signature)}
 }
 
 // Funcs returns all the packages named functions from the packages outer
 // most scope.
 func (p *Package) Funcs() []Func {
 	p.init()
+	docs, examples := p.docIndex(), p.exampleIndex()
 	var funcs []Func
 	scope := p.tc.typesPkg.Scope()
 	for _, name := range scope.Names() {
@@ -319,7 +532,9 @@ func (p *Package) Funcs() []Func {
 		if !ok {
 			continue
 		}
-		funcs = append(funcs, NewFunc(asFunc))
+		f := NewFunc(asFunc)
+		f.Doc, f.Examples = docs[name], examples[name]
+		funcs = append(funcs, f)
 	}
 	return funcs
 }
@@ -329,6 +544,11 @@ type MethodSet struct {
 	Name    string
 	Obj     types.Object
 	Methods map[string]Func
+
+	// Doc and Examples are the doc comment and examples of the named type
+	// when it was returned by a Package.
+	Doc      string
+	Examples []Example
 }
 
 // NewMethodSet returns a initialized MethodSet.
@@ -350,6 +570,17 @@ func (m MethodSet) Names() []string {
 	return names
 }
 
+// Render returns the declaration of each method in this MethodSet ordered by
+// name and separated by newlines, with packages qualified by q such as the
+// Qualifier of an Imports.
+func (m MethodSet) Render(q types.Qualifier) string {
+	lines := make([]string, 0, len(m.Methods))
+	for _, name := range m.Names() {
+		lines = append(lines, m.Methods[name].Render(q))
+	}
+	return strings.Join(lines, "\n")
+}
+
 // Len returns the current number of Method's within this MethodSet.
 func (m MethodSet) Len() int {
 	return len(m.Methods)
@@ -360,9 +591,10 @@ func (m MethodSet) Len() int {
 func (p *Package) Methods() map[string]MethodSet {
 	p.init()
 	methods := make(map[string]MethodSet)
+	docs, examples := p.docIndex(), p.exampleIndex()
 	scope := p.tc.typesPkg.Scope()
 	for _, name := range scope.Names() {
-		methodSet, err := p.MethodSet(name)
+		methodSet, err := p.methodSet(name, docs, examples)
 		if err != nil {
 			continue
 		}
@@ -376,6 +608,12 @@ func (p *Package) Methods() map[string]MethodSet {
 // MethodSet returns the set of methods for the given name.
 func (p *Package) MethodSet(name string) (MethodSet, error) {
 	p.init()
+	return p.methodSet(name, p.docIndex(), p.exampleIndex())
+}
+
+func (p *Package) methodSet(
+	name string, docs map[string]string, examples map[string][]Example,
+) (MethodSet, error) {
 	obj := p.tc.typesPkg.Scope().Lookup(name)
 	if obj == nil {
 		return MethodSet{}, fmt.Errorf("named type was not found")
@@ -386,6 +624,7 @@ func (p *Package) MethodSet(name string) (MethodSet, error) {
 
 	typ := obj.Type()
 	ms := NewMethodSet(name, obj)
+	ms.Doc, ms.Examples = docs[name], examples[name]
 	for _, t := range []types.Type{typ, types.NewPointer(typ)} {
 		mset := types.NewMethodSet(t)
 		for i := 0; i < mset.Len(); i++ {
@@ -394,19 +633,23 @@ func (p *Package) MethodSet(name string) (MethodSet, error) {
 			if !ok {
 				continue // must be *Var field selection
 			}
-			ms.Methods[f.Name()] = NewFunc(f)
+			fn := NewFunc(f)
+			fn.Doc = docs[name+"."+f.Name()]
+			fn.Examples = examples[name+"."+f.Name()]
+			ms.Methods[f.Name()] = fn
 		}
 	}
 	return ms, nil
 }
 
 // init is called for you by all functions and methods that return a Package
-// type, init will be ran only once within a sync.Once, multiple calls are safe.
-func (p *Package) init() (err error) {
+// type, init will be ran only once within a sync.Once, multiple calls are safe
+// and each of them returns the error of the first.
+func (p *Package) init() error {
 	p.once.Do(func() {
-		p.tc, err = p.toToolchain(p.typesInfo())
+		p.tc, p.err = p.toToolchain(p.typesInfo())
 	})
-	return err
+	return p.err
 }
 
 // toToolchain is used to initialize the package for usage.
@@ -435,17 +678,77 @@ func (p *Package) toToolchain(typesInfo *types.Info) (*toolchain, error) {
 	}
 	docPkg := doc.New(docAstPkg, p.Dir, doc.Mode(0))
 	astFiles := p.astFiles(astPkg)
+	path := p.ImportPath
+	if len(path) == 0 {
+		path = p.Name
+	}
 	conf := types.Config{Importer: importer.Default()}
-	typesPkg, err := conf.Check(p.Name, fileSet, astFiles, typesInfo)
+	typesPkg, err := conf.Check(path, fileSet, astFiles, typesInfo)
+	if err != nil {
+		// The default importer only reads compiled export data, which packages
+		// outside the standard library rarely have. Try again from source so
+		// packages within your GOPATH may import each other.
+		if typesInfo != nil {
+			typesInfo = p.typesInfo()
+		}
+		conf.Importer = importer.ForCompiler(fileSet, "source", nil)
+		typesPkg, err = conf.Check(path, fileSet, astFiles, typesInfo)
+	}
 	if err != nil {
 		return nil, err
 	}
 
 	tc.fileSet, tc.astPkg, tc.docPkg, tc.typesPkg, tc.typesInfo =
 		fileSet, astPkg, docPkg, typesPkg, typesInfo
+	tc.docFileSet, tc.xtestPkg = docFileSet, pkgs[p.Name+"_test"]
 	return tc, nil
 }
 
+// xtestInfo type checks the files of the external _test package of this
+// package, which import this package as it was type checked. A nil Info is
+// returned when there is no external _test package.
+func (p *Package) xtestInfo() (*types.Info, error) {
+	_, info, err := p.xtestCheck(p.tc)
+	return info, err
+}
+
+// xtestCheck type checks the files of the external _test package of tc, a
+// toolchain of this package.
+func (p *Package) xtestCheck(tc *toolchain) (*types.Package, *types.Info, error) {
+	if tc.xtestPkg == nil {
+		return nil, nil, nil
+	}
+	var (
+		err      error
+		typesPkg *types.Package
+		files    = p.astFiles(tc.xtestPkg)
+		path     = tc.typesPkg.Path() + "_test"
+	)
+	for _, imp := range []types.Importer{
+		importer.Default(), importer.ForCompiler(tc.fileSet, "source", nil),
+	} {
+		info := p.typesInfo()
+		conf := types.Config{Importer: xtestImporter{tc.typesPkg, imp}}
+		if typesPkg, err = conf.Check(path, tc.fileSet, files, info); err == nil {
+			return typesPkg, info, nil
+		}
+	}
+	return nil, nil, err
+}
+
+// xtestImporter imports pkg for its path and all other paths using Importer.
+type xtestImporter struct {
+	pkg *types.Package
+	types.Importer
+}
+
+func (im xtestImporter) Import(path string) (*types.Package, error) {
+	if path == im.pkg.Path() {
+		return im.pkg, nil
+	}
+	return im.Importer.Import(path)
+}
+
 func (p *Package) astFiles(astPkg *ast.Package) (out []*ast.File) {
 	for key := range astPkg.Files {
 		out = append(out, astPkg.Files[key])
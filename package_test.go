@@ -1,9 +1,11 @@
 package srcutil
 
 import (
+	"encoding/json"
 	"fmt"
 	"go/build"
 	"testing"
+	"text/template"
 )
 
 func TestPackage(t *testing.T) {
@@ -26,6 +28,65 @@ func TestPackage(t *testing.T) {
 			got := fmt.Sprintf("%p", pkg.tc)
 			teq(t, exp, got)
 		})
+		t.Run("Error", func(t *testing.T) {
+			pkg, err := ctx.Import("github.com/cstockton/go-srcutil/testdata/typeerror")
+			tmust(t, err)
+			l, err := NewLinter(LintConfig{})
+			tmust(t, err)
+			tmpl := template.Must(template.New("gen").Parse(``))
+			pattern, err := ParsePattern("$x", nil)
+			tmust(t, err)
+
+			// the error of the first call is returned by every later call
+			for i := 0; i < 2; i++ {
+				if _, err := l.Lint(pkg); err == nil {
+					t.Errorf("exp non-nil err from Lint call %d", i)
+				}
+				if _, err := Generate(tmpl, pkg); err == nil {
+					t.Errorf("exp non-nil err from Generate call %d", i)
+				}
+				if _, err := pattern.Match(pkg); err == nil {
+					t.Errorf("exp non-nil err from Match call %d", i)
+				}
+				if _, err := pkg.FileImports(); err == nil {
+					t.Errorf("exp non-nil err from FileImports call %d", i)
+				}
+				if _, err := pkg.GenerateEnum("Color", EnumOptions{}); err == nil {
+					t.Errorf("exp non-nil err from GenerateEnum call %d", i)
+				}
+				if _, err := pkg.Notes(NoteFilter{}); err == nil {
+					t.Errorf("exp non-nil err from Notes call %d", i)
+				}
+				docs := pkg.Docs()
+				if _, err := docs.Comment("Doc links such as [Color]."); err == nil {
+					t.Errorf("exp non-nil err from Docs.Comment call %d", i)
+				}
+				if _, err := docs.BrokenLinks(); err == nil {
+					t.Errorf("exp non-nil err from Docs.BrokenLinks call %d", i)
+				}
+				if _, err := pkg.Symbols(); err == nil {
+					t.Errorf("exp non-nil err from Symbols call %d", i)
+				}
+				if _, err := pkg.GenerateMocks(); err == nil {
+					t.Errorf("exp non-nil err from GenerateMocks call %d", i)
+				}
+				if _, err := UnusedExports([]*Package{pkg}, true); err == nil {
+					t.Errorf("exp non-nil err from UnusedExports call %d", i)
+				}
+				if _, err := pkg.API(); err == nil {
+					t.Errorf("exp non-nil err from API call %d", i)
+				}
+				if _, err := pkg.Export(); err == nil {
+					t.Errorf("exp non-nil err from Export call %d", i)
+				}
+				if _, err := json.Marshal(pkg); err == nil {
+					t.Errorf("exp non-nil err from MarshalJSON call %d", i)
+				}
+				if err := NewSite("", pkg).Write(t.TempDir()); err == nil {
+					t.Errorf("exp non-nil err from Site.Write call %d", i)
+				}
+			}
+		})
 	})
 	t.Run("Parsing", func(t *testing.T) {
 		t.Run("ToAst", func(t *testing.T) {
@@ -201,6 +262,7 @@ func TestFuncs(t *testing.T) {
 		m, ok := ms.Methods["MethodOne"]
 		teq(t, true, ok)
 		teq(t, "MethodOne", m.Name())
+		teq(t, "func (tpkg.PublicStruct).MethodOne()", m.String())
 	})
 }
 
@@ -243,7 +305,7 @@ func TestStructs(t *testing.T) {
 			ps    Struct
 		)
 		for _, s := range structs {
-			if s.Named.String() == `tpkg.PublicStruct` {
+			if s.Named.String() == tPkg.ImportPath+`.PublicStruct` {
 				ps, found = s, true
 			}
 		}
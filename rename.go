@@ -0,0 +1,418 @@
+package srcutil
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"strings"
+)
+
+// RenameError is returned by Rename when renaming would change the meaning of
+// or break the packages.
+type RenameError struct {
+	Conflicts []Diagnostic
+}
+
+// Error implements error.
+func (e *RenameError) Error() string {
+	lines := make([]string, len(e.Conflicts))
+	for i, d := range e.Conflicts {
+		lines[i] = d.String()
+	}
+	return "rename: conflicts found:\n\t" + strings.Join(lines, "\n\t")
+}
+
+// Rename returns a Patch renaming obj, which must be declared within this
+// package, to newName along with each reference to it within this package and
+// pkgs, including their external _test packages. The object may be one
+// returned by the Defs or Uses of ToInfo, or the Obj of a MethodSet and
+// similar.
+//
+// A *RenameError is returned when renaming would result in a conflict such as
+// a collision with a name declared in the same scope or as a field or method
+// of the same type, a reference resolving to a different object, unexporting a
+// name referred to by other packages, a method no longer implementing an
+// interface, or an embedded type changing the name of a field. Packages which
+// are not given in pkgs are not checked.
+func (p *Package) Rename(obj types.Object, newName string, pkgs ...*Package) (*Patch, error) {
+	if err := p.init(); err != nil {
+		return nil, err
+	}
+	if obj == nil || !obj.Pos().IsValid() {
+		return nil, fmt.Errorf("rename: object has no declaration")
+	}
+	if !token.IsIdentifier(newName) || newName == "_" {
+		return nil, fmt.Errorf("rename: %q is not a valid identifier", newName)
+	}
+	if obj.Name() == newName {
+		return nil, fmt.Errorf("rename: %v is already named %v", obj.Name(), newName)
+	}
+	if _, ok := obj.(*types.PkgName); ok {
+		return nil, fmt.Errorf("rename: renaming imports is not supported")
+	}
+
+	// Positions of the files of p are the same for each type check of p, the
+	// identifier at the position of obj must declare an object by its name.
+	var declared bool
+	for ident, def := range p.tc.typesInfo.Defs {
+		if def != nil && ident.Pos() == obj.Pos() && ident.Name == obj.Name() {
+			declared = true
+		}
+	}
+	if !declared {
+		return nil, fmt.Errorf("rename: %v is not declared by package %v", obj.Name(), p.Name)
+	}
+
+	r := &renamer{key: posKey(p.tc.fileSet, obj.Pos()), from: obj.Name(), to: newName}
+	seen := make(map[string]bool)
+	for _, pkg := range append([]*Package{p}, pkgs...) {
+		if seen[pkg.Dir] {
+			continue
+		}
+		seen[pkg.Dir] = true
+		e, err := pkg.Editor()
+		if err != nil {
+			return nil, err
+		}
+		r.add(e)
+		xtest, err := pkg.xtestEditor()
+		if err != nil {
+			return nil, err
+		}
+		if xtest != nil {
+			r.add(xtest)
+		}
+	}
+	if r.obj == nil {
+		return nil, fmt.Errorf("rename: %v is not declared by package %v", obj.Name(), p.Name)
+	}
+
+	r.checkCollisions()
+	r.checkReferences()
+	r.checkExported()
+	r.checkMethods()
+	r.checkEmbedded()
+	if len(r.conflicts) > 0 {
+		sortDiagnostics(r.conflicts)
+		return nil, &RenameError{Conflicts: r.conflicts}
+	}
+
+	var editors []*Editor
+	for _, e := range r.editors {
+		if len(r.refs[e]) == 0 {
+			continue
+		}
+		for _, ident := range r.refs[e] {
+			if err := e.Edit(ident.Pos(), ident.End(), newName); err != nil {
+				return nil, err
+			}
+		}
+		editors = append(editors, e)
+	}
+	return NewPatch(editors...), nil
+}
+
+// posKey returns the position of pos as a string, which is the same for each
+// package that refers to it since imports are type checked from source.
+func posKey(fset *token.FileSet, pos token.Pos) string {
+	position := fset.Position(pos)
+	return fmt.Sprintf("%s:%d", position.Filename, position.Offset)
+}
+
+type renamer struct {
+	key, from, to string
+	editors       []*Editor
+	refs          map[*Editor][]*ast.Ident
+	conflicts     []Diagnostic
+
+	// decl is the Editor of the declaring package and obj the renamed object
+	// within it.
+	decl *Editor
+	obj  types.Object
+}
+
+func (r *renamer) conflict(e *Editor, pos token.Pos, format string, args ...interface{}) {
+	r.conflicts = append(r.conflicts, Diagnostic{
+		Pos: e.Fset.Position(pos), Category: "rename", Message: fmt.Sprintf(format, args...)})
+}
+
+// add adds the identifiers of e that declare or refer to the renamed object.
+func (r *renamer) add(e *Editor) {
+	if r.refs == nil {
+		r.refs = make(map[*Editor][]*ast.Ident)
+	}
+	r.editors = append(r.editors, e)
+	for _, f := range e.Files() {
+		ast.Inspect(f, func(n ast.Node) bool {
+			ident, ok := n.(*ast.Ident)
+			if !ok {
+				return true
+			}
+			if obj := e.Info.Defs[ident]; obj != nil && r.is(e, obj) {
+				r.refs[e] = append(r.refs[e], ident)
+				if r.obj == nil {
+					r.decl, r.obj = e, obj
+				}
+			} else if obj := e.Info.Uses[ident]; obj != nil && r.is(e, obj) {
+				r.refs[e] = append(r.refs[e], ident)
+			}
+			return true
+		})
+	}
+}
+
+func (r *renamer) is(e *Editor, obj types.Object) bool {
+	return obj.Name() == r.from && obj.Pos().IsValid() && posKey(e.Fset, obj.Pos()) == r.key
+}
+
+// checkCollisions reports names that would be declared twice.
+func (r *renamer) checkCollisions() {
+	e, obj := r.decl, r.obj
+	if scope := obj.Parent(); scope != nil {
+		if other := scope.Lookup(r.to); other != nil {
+			r.conflict(e, obj.Pos(), "%v conflicts with %v declared at %v",
+				r.to, other.Name(), e.Fset.Position(other.Pos()))
+		}
+		if scope != e.Types.Scope() {
+			return
+		}
+		for _, f := range e.Files() {
+			if fs := e.Info.Scopes[f]; fs != nil && fs.Lookup(r.to) != nil {
+				r.conflict(e, obj.Pos(), "%v conflicts with an import in %v",
+					r.to, e.Fset.Position(f.Package).Filename)
+			}
+		}
+		return
+	}
+
+	// fields and methods collide with the fields and methods of their type
+	var typ types.Type
+	if f, ok := obj.(*types.Func); ok {
+		if recv := f.Type().(*types.Signature).Recv(); recv != nil {
+			typ = recv.Type()
+		}
+	} else if v, ok := obj.(*types.Var); ok && v.IsField() {
+		typ = r.fieldOwner(v)
+	}
+	if typ == nil {
+		return
+	}
+	if other, _, _ := types.LookupFieldOrMethod(typ, true, obj.Pkg(), r.to); other != nil {
+		r.conflict(e, obj.Pos(), "%v conflicts with the %v declared at %v",
+			r.to, objKind(other), e.Fset.Position(other.Pos()))
+	}
+}
+
+// fieldOwner returns the named type declaring the field v, or its struct when
+// it is not named.
+func (r *renamer) fieldOwner(v *types.Var) types.Type {
+	var out types.Type
+	for _, f := range r.decl.Files() {
+		ast.Inspect(f, func(n ast.Node) bool {
+			st, ok := n.(*ast.StructType)
+			if !ok || out != nil || v.Pos() < st.Pos() || v.Pos() >= st.End() {
+				return out == nil
+			}
+			for _, field := range st.Fields.List {
+				for _, name := range field.Names {
+					if name.Pos() == v.Pos() {
+						out = r.decl.Info.TypeOf(st)
+					}
+				}
+				if len(field.Names) == 0 && field.Type.Pos() <= v.Pos() && v.Pos() < field.Type.End() {
+					out = r.decl.Info.TypeOf(st)
+				}
+			}
+			return true
+		})
+	}
+	if out == nil {
+		return nil
+	}
+	scope := r.decl.Types.Scope()
+	for _, name := range scope.Names() {
+		if tn, ok := scope.Lookup(name).(*types.TypeName); ok && tn.Type().Underlying() == out {
+			return tn.Type()
+		}
+	}
+	return out
+}
+
+// checkReferences reports references to the renamed object which would
+// resolve to a different object, and references to other objects named the
+// new name which would resolve to the renamed object.
+func (r *renamer) checkReferences() {
+	e, obj, declScope := r.decl, r.obj, r.obj.Parent()
+	if declScope == nil {
+		return
+	}
+	selected := make(map[*ast.Ident]bool)
+	for _, f := range e.Files() {
+		ast.Inspect(f, func(n ast.Node) bool {
+			if sel, ok := n.(*ast.SelectorExpr); ok {
+				selected[sel.Sel] = true
+			}
+			return true
+		})
+	}
+
+	for _, ident := range r.refs[e] {
+		if selected[ident] || e.Info.Defs[ident] != nil {
+			continue
+		}
+		inner := e.Types.Scope().Innermost(ident.Pos())
+		if inner == nil {
+			continue
+		}
+		if scope, other := inner.LookupParent(r.to, ident.Pos()); other != nil && within(scope, declScope) {
+			r.conflict(e, ident.Pos(), "reference to %v would refer to %v declared at %v",
+				r.from, r.to, e.Fset.Position(other.Pos()))
+		}
+	}
+
+	for ident, other := range e.Info.Uses {
+		if ident.Name != r.to || selected[ident] || other.Parent() == nil {
+			continue
+		}
+		inner := e.Types.Scope().Innermost(ident.Pos())
+		if inner == nil || !within(inner, declScope) && inner != declScope {
+			continue
+		}
+		if other.Parent() == declScope || within(other.Parent(), declScope) {
+			continue
+		}
+		if declScope != e.Types.Scope() && obj.Pos() > ident.Pos() {
+			continue
+		}
+		r.conflict(e, ident.Pos(), "reference to %v would refer to the renamed %v",
+			r.to, r.from)
+	}
+}
+
+// within returns true if scope is nested within parent.
+func within(scope, parent *types.Scope) bool {
+	for s := scope.Parent(); s != nil; s = s.Parent() {
+		if s == parent {
+			return true
+		}
+	}
+	return false
+}
+
+// checkExported reports references from other packages when the new name is
+// not exported.
+func (r *renamer) checkExported() {
+	if token.IsExported(r.to) || !r.obj.Exported() {
+		return
+	}
+	for _, e := range r.editors {
+		if e == r.decl || len(r.refs[e]) == 0 {
+			continue
+		}
+		r.conflict(e, r.refs[e][0].Pos(), "%v is referred to by package %v and %v is not exported",
+			r.from, e.Types.Path(), r.to)
+	}
+}
+
+// checkMethods reports interfaces which would no longer be implemented by the
+// types of the packages.
+func (r *renamer) checkMethods() {
+	if _, ok := r.obj.(*types.Func); !ok {
+		return
+	}
+	for _, e := range r.editors {
+		var named, ifaces []*types.TypeName
+		scopes := []*types.Scope{e.Types.Scope()}
+		for _, imp := range e.Types.Imports() {
+			scopes = append(scopes, imp.Scope())
+		}
+		for i, scope := range scopes {
+			for _, name := range scope.Names() {
+				tn, ok := scope.Lookup(name).(*types.TypeName)
+				if !ok || tn.IsAlias() {
+					continue
+				}
+				if types.IsInterface(tn.Type()) {
+					ifaces = append(ifaces, tn)
+				} else if i == 0 {
+					named = append(named, tn)
+				}
+			}
+		}
+
+		for _, iface := range ifaces {
+			typ := iface.Type().Underlying().(*types.Interface)
+			m := r.method(typ)
+			if m == nil {
+				continue
+			}
+			for _, tn := range named {
+				ptr := types.NewPointer(tn.Type())
+				if !types.Implements(ptr, typ) {
+					continue
+				}
+				impl, _, _ := types.LookupFieldOrMethod(ptr, false, m.Pkg(), m.Name())
+				if !declaredOn(impl, tn) {
+					continue // promoted methods are reported for the embedded type
+				}
+				switch {
+				case r.is(e, m) && !r.is(e, impl):
+					r.conflict(e, tn.Pos(), "%v implements %v, its method %v would not be renamed",
+						tn.Name(), iface.Name(), r.from)
+				case !r.is(e, m) && r.is(e, impl):
+					r.conflict(e, tn.Pos(), "%v would no longer implement %v",
+						tn.Name(), iface.Name())
+				}
+			}
+		}
+	}
+}
+
+// declaredOn returns true if obj is a method declared on the named type tn.
+func declaredOn(obj types.Object, tn *types.TypeName) bool {
+	f, ok := obj.(*types.Func)
+	if !ok {
+		return false
+	}
+	typ := f.Type().(*types.Signature).Recv().Type()
+	if ptr, ok := typ.(*types.Pointer); ok {
+		typ = ptr.Elem()
+	}
+	named, ok := typ.(*types.Named)
+	return ok && named.Obj() == tn
+}
+
+// method returns the method of typ named the old name, or nil.
+func (r *renamer) method(typ *types.Interface) *types.Func {
+	for i := 0; i < typ.NumMethods(); i++ {
+		if m := typ.Method(i); m.Name() == r.from {
+			return m
+		}
+	}
+	return nil
+}
+
+// checkEmbedded reports fields which embed the renamed type, since the name
+// of the field would change.
+func (r *renamer) checkEmbedded() {
+	if _, ok := r.obj.(*types.TypeName); !ok {
+		return
+	}
+	for _, e := range r.editors {
+		for ident, obj := range e.Info.Defs {
+			v, ok := obj.(*types.Var)
+			if !ok || !v.Embedded() {
+				continue
+			}
+			typ := v.Type()
+			if ptr, ok := typ.(*types.Pointer); ok {
+				typ = ptr.Elem()
+			}
+			if named, ok := typ.(*types.Named); ok && r.is(e, named.Obj()) {
+				r.conflict(e, ident.Pos(), "%v is embedded and the name of the field would change",
+					r.from)
+			}
+		}
+	}
+}
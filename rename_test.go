@@ -0,0 +1,199 @@
+package srcutil
+
+import (
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRename(t *testing.T) {
+	const (
+		rename = "github.com/cstockton/go-srcutil/testdata/rename"
+		user   = rename + "/user"
+	)
+	pkgs, err := FromWorkDir().ImportAll(rename, user)
+	tmust(t, err)
+	pkg, usr := pkgs[0], pkgs[1]
+	info, typesPkg, err := pkg.ToInfo()
+	tmust(t, err)
+
+	scope := typesPkg.Scope()
+	lookup := func(name string) types.Object {
+		if i := strings.Index(name, "."); i > 0 {
+			obj, _, _ := types.LookupFieldOrMethod(
+				types.NewPointer(scope.Lookup(name[:i]).Type()), true, typesPkg, name[i+1:])
+			return obj
+		}
+		return scope.Lookup(name)
+	}
+	local := func(name string) types.Object {
+		for ident, obj := range info.Defs {
+			if ident.Name == name {
+				return obj
+			}
+		}
+		t.Fatalf("exp local %v", name)
+		return nil
+	}
+	conflicts := func(t *testing.T, err error, exp ...string) {
+		t.Helper()
+		rerr, ok := err.(*RenameError)
+		if !ok {
+			t.Fatalf("exp *RenameError; got %v", err)
+		}
+		var got []string
+		for _, d := range rerr.Conflicts {
+			teq(t, "rename", d.Category)
+			got = append(got, d.Message)
+		}
+		teq(t, exp, got)
+	}
+
+	t.Run("Func", func(t *testing.T) {
+		patch, err := pkg.Rename(lookup("Greet"), "Salute", usr)
+		tmust(t, err)
+		teq(t, filepath.Join(pkg.Dir), patch.Dir)
+		teq(t, 3, len(patch.Editors))
+
+		diff, err := patch.Diff()
+		tmust(t, err)
+		for _, line := range []string{
+			"--- a/rename.go", "+++ b/rename.go",
+			"-func Greet(name string) string {", "+func Salute(name string) string {",
+			`-func helper() string { return Greet("x") }`,
+			`+func helper() string { return Salute("x") }`,
+			"--- a/user/user.go", "+++ b/user/user.go",
+			"-\treturn rename.Greet(p.First)", "+\treturn rename.Salute(p.First)",
+			"--- a/rename_test.go", "+++ b/rename_test.go",
+			`-	if got := rename.Greet("x"); len(got) == 0 {`,
+			`+	if got := rename.Salute("x"); len(got) == 0 {`,
+		} {
+			if !strings.Contains(diff, "\n"+line+"\n") && !strings.HasPrefix(diff, line+"\n") {
+				t.Fatalf("exp diff to contain %q; got:\n%v", line, diff)
+			}
+		}
+	})
+	t.Run("Local", func(t *testing.T) {
+		patch, err := pkg.Rename(local("msg"), "s")
+		tmust(t, err)
+		src, err := patch.Editors[0].Source("rename.go")
+		tmust(t, err)
+		for _, s := range []string{
+			`s := Greeting + " " + name`, "if len(s) > 10 {",
+			"trimmed := strings.TrimSpace(s)", "return trimmed + s\n", "return s\n",
+		} {
+			if !strings.Contains(string(src), s) {
+				t.Fatalf("exp source to contain %q; got:\n%s", s, src)
+			}
+		}
+	})
+	t.Run("Field", func(t *testing.T) {
+		patch, err := pkg.Rename(lookup("Person.First"), "Given", usr)
+		tmust(t, err)
+		diff, err := patch.Diff()
+		tmust(t, err)
+		for _, s := range []string{
+			"+\tGiven, Last string", `+func (p *Person) Name() string { return p.Given + " " + p.Last }`,
+			"+\treturn rename.Greet(p.Given)",
+		} {
+			if !strings.Contains(diff, s) {
+				t.Fatalf("exp diff to contain %q; got:\n%v", s, diff)
+			}
+		}
+	})
+	t.Run("Conflicts", func(t *testing.T) {
+		_, err := pkg.Rename(lookup("Greet"), "Greeting")
+		conflicts(t, err, "Greeting conflicts with Greeting declared at "+
+			filepath.Join(pkg.Dir, "rename.go")+":7:7")
+
+		_, err = pkg.Rename(lookup("Greet"), "strings")
+		conflicts(t, err,
+			"strings conflicts with an import in "+filepath.Join(pkg.Dir, "rename.go"),
+			"reference to Greet would refer to strings declared at "+
+				filepath.Join(pkg.Dir, "rename.go")+":4:8",
+			"Greet is referred to by package "+rename+"_test and strings is not exported")
+
+		_, err = pkg.Rename(local("msg"), "trimmed")
+		conflicts(t, err, "reference to msg would refer to trimmed declared at "+
+			filepath.Join(pkg.Dir, "rename.go")+":13:3")
+
+		_, err = pkg.Rename(local("trimmed"), "len")
+		teq(t, nil, err)
+		_, err = pkg.Rename(local("msg"), "len")
+		conflicts(t, err, "reference to len would refer to the renamed msg")
+
+		_, err = pkg.Rename(lookup("Greeting"), "name")
+		conflicts(t, err, "reference to Greeting would refer to name declared at "+
+			filepath.Join(pkg.Dir, "rename.go")+":10:12")
+
+		_, err = pkg.Rename(lookup("Greet"), "greet", usr)
+		conflicts(t, err,
+			"Greet is referred to by package "+rename+"_test and greet is not exported",
+			"Greet is referred to by package "+user+" and greet is not exported")
+		_, err = pkg.Rename(lookup("Greeting"), "greeting")
+		teq(t, nil, err)
+
+		_, err = pkg.Rename(lookup("Person.First"), "Last")
+		conflicts(t, err, "Last conflicts with the field declared at "+
+			filepath.Join(pkg.Dir, "rename.go")+":26:9")
+		_, err = pkg.Rename(lookup("Person.Full"), "Name")
+		conflicts(t, err, "Name conflicts with the method declared at "+
+			filepath.Join(pkg.Dir, "rename.go")+":30:18")
+
+		_, err = pkg.Rename(lookup("Person.Name"), "FullName", usr)
+		conflicts(t, err, "Person would no longer implement Namer")
+
+		namer := lookup("Namer").Type().Underlying().(*types.Interface).Method(0)
+		_, err = pkg.Rename(namer, "Named", usr)
+		conflicts(t, err,
+			"Person implements Namer, its method Name would not be renamed",
+			"Bot implements Namer, its method Name would not be renamed")
+
+		_, err = pkg.Rename(lookup("Person"), "Human")
+		conflicts(t, err, "Person is embedded and the name of the field would change")
+	})
+	t.Run("Failure", func(t *testing.T) {
+		for _, name := range []string{"", "_", "func", "1x"} {
+			if _, err := pkg.Rename(lookup("Greet"), name); err == nil {
+				t.Fatalf("exp non-nil err for %q", name)
+			}
+		}
+		if _, err := pkg.Rename(lookup("Greet"), "Greet"); err == nil {
+			t.Fatal("exp non-nil err for same name")
+		}
+		if _, err := pkg.Rename(types.Universe.Lookup("len"), "length"); err == nil {
+			t.Fatal("exp non-nil err for universe object")
+		}
+		if _, err := usr.Rename(lookup("Greet"), "Salute"); err == nil {
+			t.Fatal("exp non-nil err for object declared by another package")
+		}
+	})
+	t.Run("Write", func(t *testing.T) {
+		dir := t.TempDir()
+		src, err := os.ReadFile(filepath.Join(pkg.Dir, "rename.go"))
+		tmust(t, err)
+		tmust(t, os.WriteFile(filepath.Join(dir, "rename.go"), src, 0644))
+		tmp, err := FromDir(dir).Import(".")
+		tmust(t, err)
+		tinfo, _, err := tmp.ToInfo()
+		tmust(t, err)
+		var obj types.Object
+		for ident, def := range tinfo.Defs {
+			if ident.Name == "helper" {
+				obj = def
+			}
+		}
+
+		patch, err := tmp.Rename(obj, "assist")
+		tmust(t, err)
+		_, err = patch.Write()
+		tmust(t, err)
+		got, err := os.ReadFile(filepath.Join(dir, "rename.go"))
+		tmust(t, err)
+		if !strings.Contains(string(got), "func assist() string") {
+			t.Fatalf("exp helper to be renamed; got:\n%s", got)
+		}
+	})
+}
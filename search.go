@@ -0,0 +1,656 @@
+package srcutil
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Pattern is a Go expression containing wildcards which is matched against
+// the expressions of packages, like the rewrite rules of "gofmt -r" with
+// knowledge of types. A wildcard is a name prefixed by "$" such as "$x" which
+// matches any expression, or only expressions of a type when it is
+// constrained. Each use of a wildcard within a pattern must match equal
+// expressions, I.E. "$x == $x" does not match "a == b".
+//
+// Selectors of packages such as "fmt.Println" match uses of the package by its
+// package name, even when it has been imported by another name. Other
+// identifiers must be equal.
+type Pattern struct {
+	Source string
+
+	// Wildcards maps the names of the wildcards within Source to the type
+	// spec constraining the expressions they match, which is empty for any
+	// expression.
+	Wildcards map[string]string
+
+	expr ast.Expr
+}
+
+// PatternMatch is an expression matched by a Pattern.
+type PatternMatch struct {
+	Package *Package
+	Pos     token.Position
+	Node    ast.Expr
+
+	// Source is the source of the matched expression and Bindings the
+	// source of the expressions matched by each wildcard.
+	Source   string
+	Bindings map[string]string
+}
+
+// String implements fmt.Stringer.
+func (m PatternMatch) String() string {
+	return fmt.Sprintf("%v: %s", m.Pos, m.Source)
+}
+
+const wildcardPrefix = "srcutil_wildcard_"
+
+var wildcardRx = regexp.MustCompile(`\$([A-Za-z_][A-Za-z0-9_]*)`)
+
+// ParsePattern returns a Pattern for the Go expression pattern. Constraints
+// maps wildcard names without the "$" to the type spec of the expressions
+// they match. A type spec is the name of a predeclared type or an import path
+// followed by a type name, prefixed by any number of "*" or "[]", I.E.
+// "io.Closer", "*net/http.Request" or "[]byte". An expression matches when
+// its type is assignable to the type spec.
+func ParsePattern(pattern string, constraints map[string]string) (*Pattern, error) {
+	p := &Pattern{Source: pattern, Wildcards: make(map[string]string)}
+	for _, m := range wildcardRx.FindAllStringSubmatch(pattern, -1) {
+		p.Wildcards[m[1]] = ``
+	}
+	for name, spec := range constraints {
+		if _, ok := p.Wildcards[name]; !ok {
+			return nil, fmt.Errorf("pattern: wildcard $%v is not within %q", name, pattern)
+		}
+		if _, _, err := parseTypeSpec(spec); err != nil {
+			return nil, fmt.Errorf("pattern: wildcard $%v: %v", name, err)
+		}
+		p.Wildcards[name] = spec
+	}
+	expr, err := parseWildcardExpr(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("pattern: %v", err)
+	}
+	p.expr = expr
+	return p, nil
+}
+
+func parseWildcardExpr(src string) (ast.Expr, error) {
+	return parser.ParseExpr(wildcardRx.ReplaceAllString(src, wildcardPrefix+"$1"))
+}
+
+// Match returns the expressions of pkgs matched by this Pattern ordered by
+// position. Expressions within a match are not matched.
+func (p *Pattern) Match(pkgs ...*Package) ([]PatternMatch, error) {
+	var out []PatternMatch
+	for _, pkg := range pkgs {
+		if err := pkg.init(); err != nil {
+			return nil, err
+		}
+		files := pkg.astFiles(pkg.tc.astPkg)
+		m := p.matcher(pkg, pkg.tc.fileSet, pkg.tc.typesPkg, pkg.tc.typesInfo)
+		m.find(files, func(node ast.Expr, _ ast.Node, binds map[string]ast.Expr) {
+			match := PatternMatch{
+				Package: pkg, Pos: pkg.tc.fileSet.Position(node.Pos()), Node: node,
+				Source: m.source(node), Bindings: make(map[string]string)}
+			for name, bound := range binds {
+				match.Bindings[name] = m.source(bound)
+			}
+			out = append(out, match)
+		})
+		if m.err != nil {
+			return nil, m.err
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := out[i].Pos, out[j].Pos
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		return a.Offset < b.Offset
+	})
+	return out, nil
+}
+
+// Replace returns a Patch replacing the expressions of pkgs matched by this
+// Pattern with replacement, a Go expression which may contain the wildcards of
+// this Pattern. Wildcards are replaced by the source of the expression they
+// matched, which is parenthesized when needed.
+//
+// Selectors of packages within replacement refer to packages by their package
+// name like those of a Pattern. They are qualified by the name each edited
+// file imports the package by, and an import is added to files which do not
+// import it. An error is returned when a package can not be found or its name
+// is taken within a file.
+func (p *Pattern) Replace(replacement string, pkgs ...*Package) (*Patch, error) {
+	repl, err := parseWildcardExpr(replacement)
+	if err != nil {
+		return nil, fmt.Errorf("replace: %v", err)
+	}
+	for _, m := range wildcardRx.FindAllStringSubmatch(replacement, -1) {
+		if _, ok := p.Wildcards[m[1]]; !ok {
+			return nil, fmt.Errorf("replace: wildcard $%v is not within %q", m[1], p.Source)
+		}
+	}
+	parents := make(map[*ast.Ident]ast.Node)
+	inspectParents(repl, func(n, parent ast.Node) bool {
+		if ident, ok := n.(*ast.Ident); ok {
+			parents[ident] = parent
+		}
+		return true
+	})
+
+	var editors []*Editor
+	for _, pkg := range pkgs {
+		e, err := pkg.Editor()
+		if err != nil {
+			return nil, err
+		}
+		m := p.matcher(pkg, e.Fset, e.Types, e.Info)
+		files := e.Files()
+		imported := make(map[*ast.File]map[string]bool)
+		var edits int
+		m.find(files, func(node ast.Expr, parent ast.Node, binds map[string]ast.Expr) {
+			if m.err != nil {
+				return
+			}
+			var quals map[*ast.Ident]string
+			if quals, m.err = m.qualify(e, files, node, parents, imported); m.err != nil {
+				return
+			}
+			var text string
+			if text, m.err = m.substitute(repl, binds, parents, quals); m.err == nil {
+				root := repl
+				if ident, ok := repl.(*ast.Ident); ok && strings.HasPrefix(ident.Name, wildcardPrefix) {
+					root = binds[strings.TrimPrefix(ident.Name, wildcardPrefix)]
+				}
+				if needsParens(parent, node, root) {
+					text = "(" + text + ")"
+				}
+				m.err = e.Edit(node.Pos(), node.End(), text)
+				edits++
+			}
+		})
+		if m.err != nil {
+			return nil, m.err
+		}
+		if edits > 0 {
+			editors = append(editors, e)
+		}
+	}
+	return NewPatch(editors...), nil
+}
+
+type matcher struct {
+	pattern *Pattern
+	pkg     *Package
+	fset    *token.FileSet
+	types   *types.Package
+	info    *types.Info
+	err     error
+
+	// constraints are the resolved types of wildcards.
+	constraints map[string]types.Type
+}
+
+func (p *Pattern) matcher(pkg *Package, fset *token.FileSet, typesPkg *types.Package, info *types.Info) *matcher {
+	m := &matcher{pattern: p, pkg: pkg, fset: fset, types: typesPkg, info: info,
+		constraints: make(map[string]types.Type)}
+	for name, spec := range p.Wildcards {
+		if len(spec) == 0 {
+			continue
+		}
+		typ, err := m.resolve(spec)
+		if err != nil {
+			m.err = fmt.Errorf("pattern: wildcard $%v: %v", name, err)
+			return m
+		}
+		m.constraints[name] = typ
+	}
+	return m
+}
+
+// find calls fn with each expression within files matching the pattern, its
+// parent and the expressions bound to its wildcards.
+func (m *matcher) find(files []*ast.File, fn func(ast.Expr, ast.Node, map[string]ast.Expr)) {
+	if m.err != nil {
+		return
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Pos() < files[j].Pos() })
+	for _, f := range files {
+		inspectParents(f, func(n, parent ast.Node) bool {
+			expr, ok := n.(ast.Expr)
+			if !ok {
+				return true
+			}
+			binds := make(map[string]ast.Expr)
+			if m.match(m.pattern.expr, expr, binds) {
+				fn(expr, parent, binds)
+				return false
+			}
+			return true
+		})
+	}
+}
+
+// inspectParents is like ast.Inspect but also calls fn with the parent of
+// each node, which is nil for root.
+func inspectParents(root ast.Node, fn func(n, parent ast.Node) bool) {
+	var stack []ast.Node
+	ast.Inspect(root, func(n ast.Node) bool {
+		if n == nil {
+			stack = stack[:len(stack)-1]
+			return false
+		}
+		var parent ast.Node
+		if len(stack) > 0 {
+			parent = stack[len(stack)-1]
+		}
+		if !fn(n, parent) {
+			return false
+		}
+		stack = append(stack, n)
+		return true
+	})
+}
+
+// isOperand returns true if expr is an operand of parent which must be
+// parenthesized when it is not a primary expression.
+func isOperand(parent ast.Node, expr ast.Expr) bool {
+	switch parent := parent.(type) {
+	case *ast.BinaryExpr, *ast.UnaryExpr, *ast.StarExpr:
+		return true
+	case *ast.SelectorExpr:
+		return parent.X == expr
+	case *ast.IndexExpr:
+		return parent.X == expr
+	case *ast.SliceExpr:
+		return parent.X == expr
+	case *ast.TypeAssertExpr:
+		return parent.X == expr
+	case *ast.CallExpr:
+		return parent.Fun == expr
+	}
+	return false
+}
+
+func (m *matcher) source(node ast.Node) string {
+	var buf bytes.Buffer
+	format.Node(&buf, m.fset, node)
+	return buf.String()
+}
+
+// match returns true if node matches pattern, a node of the pattern.
+func (m *matcher) match(pattern, node ast.Node, binds map[string]ast.Expr) bool {
+	if ident, ok := pattern.(*ast.Ident); ok && strings.HasPrefix(ident.Name, wildcardPrefix) {
+		expr, ok := node.(ast.Expr)
+		if !ok {
+			return false
+		}
+		name := strings.TrimPrefix(ident.Name, wildcardPrefix)
+		if bound, ok := binds[name]; ok {
+			return m.equal(bound, expr)
+		}
+		if typ, ok := m.constraints[name]; ok {
+			tv, ok := m.info.Types[expr]
+			if !ok || tv.IsType() || tv.Type == nil || !types.AssignableTo(tv.Type, typ) {
+				return false
+			}
+		}
+		binds[name] = expr
+		return true
+	}
+
+	// package selectors match uses of the package by name
+	if sel, ok := pattern.(*ast.SelectorExpr); ok {
+		if x, ok := sel.X.(*ast.Ident); ok && !strings.HasPrefix(x.Name, wildcardPrefix) {
+			if nodeSel, ok := node.(*ast.SelectorExpr); ok {
+				if nx, ok := nodeSel.X.(*ast.Ident); ok {
+					if pkgName, ok := m.info.Uses[nx].(*types.PkgName); ok {
+						same := pkgName.Imported().Name() == x.Name
+						if xName, ok := m.info.Uses[x].(*types.PkgName); ok {
+							// x is not from a pattern when comparing bound expressions
+							same = xName.Imported() == pkgName.Imported()
+						}
+						return same && sel.Sel.Name == nodeSel.Sel.Name
+					}
+				}
+			}
+		}
+	}
+	x, y := reflect.ValueOf(pattern), reflect.ValueOf(node)
+	if x.Type() != y.Type() {
+		return false
+	}
+	return m.matchValue(x.Elem(), y.Elem(), binds)
+}
+
+var (
+	posType    = reflect.TypeOf(token.NoPos)
+	objectType = reflect.TypeOf((*ast.Object)(nil))
+	scopeType  = reflect.TypeOf((*ast.Scope)(nil))
+	nodeType   = reflect.TypeOf((*ast.Node)(nil)).Elem()
+)
+
+func (m *matcher) matchValue(x, y reflect.Value, binds map[string]ast.Expr) bool {
+	if !x.IsValid() || !y.IsValid() {
+		return x.IsValid() == y.IsValid()
+	}
+	if x.Type() != y.Type() {
+		return false
+	}
+	switch x.Type() {
+	case posType, objectType, scopeType:
+		return true
+	}
+
+	switch x.Kind() {
+	case reflect.Interface:
+		if x.IsNil() || y.IsNil() {
+			return x.IsNil() == y.IsNil()
+		}
+		if xn, ok := x.Interface().(ast.Node); ok {
+			if yn, ok := y.Interface().(ast.Node); ok {
+				return m.match(xn, yn, binds)
+			}
+		}
+		return m.matchValue(x.Elem(), y.Elem(), binds)
+	case reflect.Ptr:
+		if x.IsNil() || y.IsNil() {
+			return x.IsNil() == y.IsNil()
+		}
+		if x.Type().Implements(nodeType) {
+			if _, ok := x.Interface().(*ast.CommentGroup); ok {
+				return true
+			}
+			return m.match(x.Interface().(ast.Node), y.Interface().(ast.Node), binds)
+		}
+		return m.matchValue(x.Elem(), y.Elem(), binds)
+	case reflect.Slice:
+		if x.Len() != y.Len() {
+			return false
+		}
+		for i := 0; i < x.Len(); i++ {
+			if !m.matchValue(x.Index(i), y.Index(i), binds) {
+				return false
+			}
+		}
+		return true
+	case reflect.Struct:
+		for i := 0; i < x.NumField(); i++ {
+			if !m.matchValue(x.Field(i), y.Field(i), binds) {
+				return false
+			}
+		}
+		return true
+	}
+	return x.Interface() == y.Interface()
+}
+
+// equal returns true if x and y are the same expression.
+func (m *matcher) equal(x, y ast.Expr) bool {
+	return m.match(x, y, nil)
+}
+
+// qualify returns the names which the package selectors among parents must be
+// qualified by within the file of node, adding an import of each package the
+// file does not import. Imported records the paths imported by qualify for
+// each file.
+func (m *matcher) qualify(
+	e *Editor, files []*ast.File, node ast.Expr, parents map[*ast.Ident]ast.Node,
+	imported map[*ast.File]map[string]bool,
+) (map[*ast.Ident]string, error) {
+	var file *ast.File
+	for _, f := range files {
+		if m.fset.File(f.Pos()) == m.fset.File(node.Pos()) {
+			file = f
+		}
+	}
+	if file == nil {
+		return nil, fmt.Errorf("replace: file of %v was not found", m.fset.Position(node.Pos()))
+	}
+	scope := m.types.Scope().Innermost(node.Pos())
+
+	var idents []*ast.Ident
+	for ident, parent := range parents {
+		sel, ok := parent.(*ast.SelectorExpr)
+		if ok && sel.X == ident && !strings.HasPrefix(ident.Name, wildcardPrefix) {
+			idents = append(idents, ident)
+		}
+	}
+	sort.Slice(idents, func(i, j int) bool { return idents[i].Pos() < idents[j].Pos() })
+
+	quals := make(map[*ast.Ident]string)
+	for _, ident := range idents {
+		var found types.Object
+		if scope != nil {
+			_, found = scope.LookupParent(ident.Name, node.Pos())
+		}
+		if found != nil {
+			pkgName, ok := found.(*types.PkgName)
+			if !ok || pkgName.Imported().Name() == ident.Name {
+				continue // a local identifier or a package imported by its name
+			}
+		}
+		if name, ok := m.importedName(file, ident.Name); ok {
+			quals[ident] = name
+			continue
+		}
+
+		pkg := m.findPackage(ident.Name)
+		if pkg == nil {
+			return nil, fmt.Errorf("replace: package %v was not found", ident.Name)
+		}
+		if found != nil {
+			return nil, fmt.Errorf("replace: name %v of package %v is taken within %v",
+				ident.Name, pkg.Path(), e.filename(file))
+		}
+		if imported[file] == nil {
+			imported[file] = make(map[string]bool)
+		}
+		if !imported[file][pkg.Path()] {
+			imported[file][pkg.Path()] = true
+			if err := e.AddImport(file, ``, pkg.Path()); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return quals, nil
+}
+
+// importedName returns the name which file imports the package named pkgName
+// by.
+func (m *matcher) importedName(file *ast.File, pkgName string) (string, bool) {
+	for _, spec := range file.Imports {
+		obj := m.info.Implicits[spec]
+		if spec.Name != nil {
+			obj = m.info.Defs[spec.Name]
+		}
+		if obj, ok := obj.(*types.PkgName); ok && obj.Imported().Name() == pkgName {
+			if name := obj.Name(); name != "_" && name != "." {
+				return name, true
+			}
+		}
+	}
+	return ``, false
+}
+
+// findPackage returns the package named pkgName imported directly or
+// indirectly by the matched package, or the package with the import path
+// pkgName.
+func (m *matcher) findPackage(pkgName string) *types.Package {
+	seen := map[*types.Package]bool{m.types: true}
+	var walk func(pkgs []*types.Package) *types.Package
+	walk = func(pkgs []*types.Package) *types.Package {
+		for _, pkg := range pkgs {
+			if seen[pkg] {
+				continue
+			}
+			seen[pkg] = true
+			if pkg.Name() == pkgName {
+				return pkg
+			}
+			if found := walk(pkg.Imports()); found != nil {
+				return found
+			}
+		}
+		return nil
+	}
+	if pkg := walk(m.types.Imports()); pkg != nil {
+		return pkg
+	}
+	if pkg, err := importer.Default().Import(pkgName); err == nil && pkg.Name() == pkgName {
+		return pkg
+	}
+	return nil
+}
+
+// substitute returns the source of repl with its wildcards replaced by the
+// source of the expressions bound to them and the identifiers within quals
+// replaced by their names.
+func (m *matcher) substitute(
+	repl ast.Expr, binds map[string]ast.Expr, parents map[*ast.Ident]ast.Node,
+	quals map[*ast.Ident]string,
+) (string, error) {
+	names := make(map[*ast.Ident]string)
+	ast.Inspect(repl, func(n ast.Node) bool {
+		ident, ok := n.(*ast.Ident)
+		if !ok {
+			return true
+		}
+		if qual, ok := quals[ident]; ok {
+			names[ident] = ident.Name
+			ident.Name = qual
+		} else if strings.HasPrefix(ident.Name, wildcardPrefix) {
+			names[ident] = ident.Name
+			bound := binds[strings.TrimPrefix(ident.Name, wildcardPrefix)]
+			src := m.source(bound)
+			if needsParens(parents[ident], ident, bound) {
+				src = "(" + src + ")"
+			}
+			ident.Name = src
+		}
+		return true
+	})
+	defer func() {
+		for ident, name := range names {
+			ident.Name = name
+		}
+	}()
+
+	var buf bytes.Buffer
+	if err := format.Node(&buf, token.NewFileSet(), repl); err != nil {
+		return ``, err
+	}
+	return buf.String(), nil
+}
+
+// needsParens returns true if expr must be parenthesized when it replaces the
+// operand x of parent.
+func needsParens(parent ast.Node, x, expr ast.Expr) bool {
+	if !isOperand(parent, x) {
+		return false
+	}
+	binary, isBinary := parent.(*ast.BinaryExpr)
+	switch expr := expr.(type) {
+	case *ast.BinaryExpr:
+		return !isBinary || expr.Op.Precedence() <= binary.Op.Precedence()
+	case *ast.UnaryExpr, *ast.StarExpr:
+		return !isBinary
+	case *ast.KeyValueExpr, *ast.FuncLit:
+		return true
+	}
+	return false
+}
+
+// resolve returns the type of spec as seen by the matched package.
+func (m *matcher) resolve(spec string) (types.Type, error) {
+	wrap, name, err := parseTypeSpec(spec)
+	if err != nil {
+		return nil, err
+	}
+	var typ types.Type
+	if i := strings.LastIndex(name, "."); i < 0 {
+		obj, ok := types.Universe.Lookup(name).(*types.TypeName)
+		if !ok {
+			return nil, fmt.Errorf("%v is not a predeclared type", name)
+		}
+		typ = obj.Type()
+	} else {
+		path, typeName := name[:i], name[i+1:]
+		pkg := m.lookupPackage(path)
+		if pkg == nil {
+			if pkg, err = importer.Default().Import(path); err != nil {
+				return nil, err
+			}
+		}
+		obj, ok := pkg.Scope().Lookup(typeName).(*types.TypeName)
+		if !ok {
+			return nil, fmt.Errorf("type %v was not found in %v", typeName, path)
+		}
+		typ = obj.Type()
+	}
+	for i := len(wrap) - 1; i >= 0; i-- {
+		if wrap[i] == "*" {
+			typ = types.NewPointer(typ)
+		} else {
+			typ = types.NewSlice(typ)
+		}
+	}
+	return typ, nil
+}
+
+// lookupPackage returns the package with path imported directly or indirectly
+// by the matched package, or the matched package itself.
+func (m *matcher) lookupPackage(path string) *types.Package {
+	seen := make(map[*types.Package]bool)
+	var walk func(pkgs []*types.Package) *types.Package
+	walk = func(pkgs []*types.Package) *types.Package {
+		for _, pkg := range pkgs {
+			if seen[pkg] {
+				continue
+			}
+			seen[pkg] = true
+			if pkg.Path() == path {
+				return pkg
+			}
+			if found := walk(pkg.Imports()); found != nil {
+				return found
+			}
+		}
+		return nil
+	}
+	return walk([]*types.Package{m.types})
+}
+
+// parseTypeSpec returns the "*" and "[]" prefixes of spec and the name of the
+// type following them.
+func parseTypeSpec(spec string) (wrap []string, name string, err error) {
+	name = spec
+	for {
+		switch {
+		case strings.HasPrefix(name, "*"):
+			wrap, name = append(wrap, "*"), name[1:]
+			continue
+		case strings.HasPrefix(name, "[]"):
+			wrap, name = append(wrap, "[]"), name[2:]
+			continue
+		}
+		break
+	}
+	typeName := name[strings.LastIndex(name, ".")+1:]
+	if !token.IsIdentifier(typeName) || strings.ContainsAny(name, " \t") {
+		return nil, ``, fmt.Errorf("invalid type spec %q", spec)
+	}
+	return wrap, name, nil
+}
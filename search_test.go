@@ -0,0 +1,144 @@
+package srcutil
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPattern(t *testing.T) {
+	const search = "github.com/cstockton/go-srcutil/testdata/search"
+	pkg, err := FromWorkDir().Import(search)
+	tmust(t, err)
+
+	sources := func(t *testing.T, matches []PatternMatch) (out []string) {
+		for _, m := range matches {
+			teq(t, pkg, m.Package)
+			out = append(out, m.Source)
+		}
+		return
+	}
+
+	t.Run("Match", func(t *testing.T) {
+		tests := []struct {
+			pattern     string
+			constraints map[string]string
+			exp         []string
+		}{
+			{"$x.Close()", nil, []string{"f.Close()", "c.Close()"}},
+			{"$x.Close()", map[string]string{"x": "*os.File"}, []string{"f.Close()"}},
+			{"$x.Close()", map[string]string{"x": "io.Closer"}, []string{"f.Close()", "c.Close()"}},
+			{"$x == $x", nil, []string{
+				`len(str.TrimSpace("a")) == len(str.TrimSpace("a"))`, "n == n"}},
+			{"strings.TrimSpace($s)", nil, []string{
+				`str.TrimSpace("a")`, `str.TrimSpace("a")`}},
+			{"len($s)", map[string]string{"s": "string"}, []string{
+				`len(str.TrimSpace("a"))`, `len(str.TrimSpace("a"))`,
+				`len("a" + "b")`, "len(buf.String())", "len(a + b)"}},
+			{"len($b)", map[string]string{"b": "[]byte"}, nil},
+			{"$x.WriteString($y)", map[string]string{"x": "*bytes.Buffer"}, nil},
+			{"$x.WriteString($y)", map[string]string{"x": "bytes.Buffer"}, []string{`buf.WriteString("x")`}},
+			{"fmt.Println($x)", nil, nil},
+		}
+		for _, test := range tests {
+			p, err := ParsePattern(test.pattern, test.constraints)
+			tmust(t, err)
+			matches, err := p.Match(pkg)
+			tmust(t, err)
+			teq(t, test.exp, sources(t, matches))
+		}
+
+		p, err := ParsePattern("$x.Close()", map[string]string{"x": "*os.File"})
+		tmust(t, err)
+		matches, err := p.Match(pkg)
+		tmust(t, err)
+		teq(t, map[string]string{"x": "f"}, matches[0].Bindings)
+		teq(t, 19, matches[0].Pos.Line)
+		if !strings.HasSuffix(matches[0].String(), "search.go:19:2: f.Close()") {
+			t.Fatalf("unexpected String: %v", matches[0])
+		}
+	})
+	t.Run("Replace", func(t *testing.T) {
+		tests := []struct {
+			pattern, replacement string
+			constraints          map[string]string
+			exp                  []string
+		}{
+			{"$x.Close()", "closeAll($x)", map[string]string{"x": "*os.File"},
+				[]string{"-\tf.Close()", "+\tcloseAll(f)", " \tc.Close()"}},
+			{"len($s)", "count($s)", map[string]string{"s": "string"},
+				[]string{`+	return count("a"+"b") * count(buf.String()), nil`}},
+			{"add($a, $b)", "len($a) + len($b)", nil,
+				[]string{`+	n := 2 * (len(f.Name()) + len("y"))`}},
+			{"len($s)", "-$s", nil,
+				[]string{`+	return -("a" + "b") * -buf.String(), nil`}},
+			{"len($s)", "$s", nil,
+				[]string{`+	return ("a" + "b") * buf.String(), nil`,
+					`+func add(a, b string) int { return a + b }`}},
+			{"add($a, $b)", "len($a) * len($b)", nil,
+				[]string{`+	n := 2 * (len(f.Name()) * len("y"))`}},
+			{"strings.TrimSpace($s)", "strings.ToUpper($s)", nil,
+				[]string{`+	if len(str.ToUpper("a")) == len(str.ToUpper("a")) {`}},
+			{"len(buf.String())", "buf.Len()", nil,
+				[]string{`+	return len("a"+"b") * buf.Len(), nil`}},
+			{"$x.Close()", "errors.Unwrap($x.Close())", map[string]string{"x": "*os.File"},
+				[]string{"+	\"errors\"", "+	errors.Unwrap(f.Close())"}},
+		}
+		for _, test := range tests {
+			p, err := ParsePattern(test.pattern, test.constraints)
+			tmust(t, err)
+			patch, err := p.Replace(test.replacement, pkg)
+			tmust(t, err)
+			diff, err := patch.Diff()
+			tmust(t, err)
+			for _, line := range test.exp {
+				if !strings.Contains(diff, "\n"+line+"\n") {
+					t.Fatalf("%v -> %v: exp diff to contain %q; got:\n%v",
+						test.pattern, test.replacement, line, diff)
+				}
+			}
+		}
+
+		p, err := ParsePattern("fmt.Println($x)", nil)
+		tmust(t, err)
+		patch, err := p.Replace("log.Print($x)", pkg)
+		tmust(t, err)
+		teq(t, 0, len(patch.Editors))
+	})
+	t.Run("Failure", func(t *testing.T) {
+		for _, test := range []struct {
+			pattern     string
+			constraints map[string]string
+		}{
+			{"$x +", nil},
+			{"$x.Close()", map[string]string{"y": "error"}},
+			{"$x.Close()", map[string]string{"x": "io.Closer Reader"}},
+			{"$x.Close()", map[string]string{"x": "*"}},
+		} {
+			if _, err := ParsePattern(test.pattern, test.constraints); err == nil {
+				t.Fatalf("%v %v: exp non-nil err", test.pattern, test.constraints)
+			}
+		}
+
+		p, err := ParsePattern("$x.Close()", map[string]string{"x": "Closer"})
+		tmust(t, err)
+		if _, err = p.Match(pkg); err == nil {
+			t.Fatal("exp non-nil err for unknown predeclared type")
+		}
+		p, err = ParsePattern("$x.Close()", map[string]string{"x": "io.Closr"})
+		tmust(t, err)
+		if _, err = p.Match(pkg); err == nil {
+			t.Fatal("exp non-nil err for unknown type")
+		}
+		p, err = ParsePattern("$x.Close()", nil)
+		tmust(t, err)
+		if _, err = p.Replace("$y.Close()", pkg); err == nil {
+			t.Fatal("exp non-nil err for unknown wildcard")
+		}
+		if _, err = p.Replace("$x.Close(", pkg); err == nil {
+			t.Fatal("exp non-nil err for invalid replacement")
+		}
+		if _, err = p.Replace("nosuchpkg.Close($x)", pkg); err == nil {
+			t.Fatal("exp non-nil err for unknown package")
+		}
+	})
+}
@@ -0,0 +1,441 @@
+package srcutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/ast"
+	"go/doc"
+	"go/doc/comment"
+	"go/token"
+	"html/template"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Site generates a static HTML documentation site for a set of packages that
+// may be browsed offline. It contains an index page listing each package, a
+// page for each package similar to Docs.Markdown, a page for each source file
+// with an anchor for each line, I.E. "#L42", and a search index.
+//
+// The search index is written to "search.json" as a JSON array of objects
+// with the "name", "kind", "package", "synopsis" and "url" keys, where url is
+// relative to the root of the site.
+type Site struct {
+	// Title is used for the index page, it defaults to "Packages".
+	Title    string
+	Packages []*Package
+}
+
+// NewSite returns a Site for the given packages.
+func NewSite(title string, pkgs ...*Package) *Site {
+	return &Site{Title: title, Packages: pkgs}
+}
+
+// Write generates the site into dir, creating it if it does not exist. Files
+// within dir that are not part of the site are left untouched. Nothing is
+// written when any of the packages could not be type checked.
+func (s *Site) Write(dir string) error {
+	files, err := s.render()
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		filename := filepath.Join(dir, filepath.FromSlash(name))
+		if err = os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+			return err
+		}
+		if err = os.WriteFile(filename, files[name], 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SearchEntry is a single entry within the search index of a Site.
+type SearchEntry struct {
+	Name     string `json:"name"`
+	Kind     string `json:"kind"`
+	Package  string `json:"package"`
+	Synopsis string `json:"synopsis,omitempty"`
+	URL      string `json:"url"`
+}
+
+// render returns the content of each file of the site keyed by its slash
+// separated path relative to the root of the site.
+func (s *Site) render() (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	pages := make(map[string]string)
+	for _, pkg := range s.Packages {
+		if err := pkg.init(); err != nil {
+			return nil, err
+		}
+		pages[pkg.ImportPath] = sitePackagePath(pkg.ImportPath)
+	}
+
+	title := s.Title
+	if len(title) == 0 {
+		title = "Packages"
+	}
+	index := siteIndex{sitePage: sitePage{Title: title, Root: "."}}
+	var search []SearchEntry
+	for _, pkg := range s.Packages {
+		sp := newSitePackage(pkg, pages)
+		index.Packages = append(index.Packages, siteLink{
+			Name: pkg.ImportPath, URL: sp.Path, Synopsis: pkg.Synopsis()})
+		search = append(search, sp.search...)
+
+		var buf bytes.Buffer
+		if err := siteTemplates.ExecuteTemplate(&buf, "package", sp); err != nil {
+			return nil, err
+		}
+		files[sp.Path] = buf.Bytes()
+
+		for _, filename := range pkg.tc.docPkg.Filenames {
+			data, err := os.ReadFile(filename)
+			if err != nil {
+				return nil, err
+			}
+			src := newSiteSource(pkg, filename, string(data))
+			buf = bytes.Buffer{}
+			if err = siteTemplates.ExecuteTemplate(&buf, "source", src); err != nil {
+				return nil, err
+			}
+			files[src.Path] = buf.Bytes()
+		}
+	}
+	sort.Slice(index.Packages, func(i, j int) bool {
+		return index.Packages[i].Name < index.Packages[j].Name
+	})
+
+	var buf bytes.Buffer
+	if err := siteTemplates.ExecuteTemplate(&buf, "index", index); err != nil {
+		return nil, err
+	}
+	files["index.html"] = buf.Bytes()
+
+	sort.SliceStable(search, func(i, j int) bool {
+		return search[i].Name < search[j].Name
+	})
+	data, err := json.MarshalIndent(search, ``, `  `)
+	if err != nil {
+		return nil, err
+	}
+	files["search.json"] = data
+	return files, nil
+}
+
+func sitePackagePath(importPath string) string {
+	return path.Join("pkg", importPath, "index.html")
+}
+
+func siteSourcePath(importPath, filename string) string {
+	return path.Join("src", importPath, filepath.Base(filename)+".html")
+}
+
+// siteRel returns the relative URL from the page at from to the page at to.
+func siteRel(from, to string) string {
+	rel, err := filepath.Rel(filepath.FromSlash(path.Dir(from)), filepath.FromSlash(to))
+	if err != nil {
+		return to
+	}
+	return filepath.ToSlash(rel)
+}
+
+type sitePage struct {
+	Title string
+
+	// Root is the relative URL of the root of the site from this page.
+	Root string
+}
+
+type siteLink struct {
+	Name, URL, Synopsis string
+}
+
+type siteIndex struct {
+	sitePage
+	Packages []siteLink
+}
+
+type siteSource struct {
+	sitePage
+	Path    string
+	Package siteLink
+	Lines   []string
+}
+
+func newSiteSource(pkg *Package, filename, data string) *siteSource {
+	src := &siteSource{Path: siteSourcePath(pkg.ImportPath, filename)}
+	src.Title = path.Join(pkg.ImportPath, filepath.Base(filename))
+	src.Root = siteRel(src.Path, ".")
+	src.Package = siteLink{
+		Name: pkg.ImportPath, URL: siteRel(src.Path, sitePackagePath(pkg.ImportPath))}
+	src.Lines = strings.Split(strings.TrimSuffix(data, "\n"), "\n")
+	return src
+}
+
+type siteExample struct {
+	Title, Code, Output string
+	Doc                 template.HTML
+}
+
+type siteDecl struct {
+	ID, Name, Code, Src string
+	Doc                 template.HTML
+	Examples            []siteExample
+}
+
+type siteType struct {
+	siteDecl
+	Decls []siteDecl
+}
+
+type sitePackage struct {
+	sitePage
+	Path     string
+	Pkg      *Package
+	Doc      template.HTML
+	Examples []siteExample
+	Files    []siteLink
+	Consts   []siteDecl
+	Vars     []siteDecl
+	Funcs    []siteDecl
+	Types    []siteType
+
+	docPkg   *doc.Package
+	fileSet  *token.FileSet
+	printer  *comment.Printer
+	examples map[string][]siteExample
+	search   []SearchEntry
+}
+
+func newSitePackage(pkg *Package, pages map[string]string) *sitePackage {
+	sp := &sitePackage{
+		Path:     sitePackagePath(pkg.ImportPath),
+		Pkg:      pkg,
+		docPkg:   pkg.tc.docPkg,
+		fileSet:  pkg.tc.docFileSet,
+		examples: make(map[string][]siteExample),
+	}
+	sp.Title, sp.Root = pkg.ImportPath, siteRel(sp.Path, ".")
+	sp.printer = sp.docPkg.Printer()
+	sp.printer.HeadingLevel = 3
+	sp.printer.DocLinkURL = func(link *comment.DocLink) string {
+		page, ok := pages[link.ImportPath]
+		if !ok || link.ImportPath == pkg.ImportPath {
+			return docLinkURL(pkg.ImportPath, link)
+		}
+		url := siteRel(sp.Path, page)
+		if name := docLinkName(link); len(name) > 0 {
+			url += "#" + name
+		}
+		return url
+	}
+
+	for id, examples := range pkg.exampleIndex() {
+		for _, ex := range examples {
+			title := "Example"
+			if len(ex.Suffix) > 0 {
+				title += " (" + ex.Suffix + ")"
+			}
+			sp.examples[id] = append(sp.examples[id], siteExample{
+				Title: title, Doc: sp.html(ex.Doc), Output: ex.Output,
+				Code: exampleCode(pkg.tc.fileSet, ex.Example)})
+		}
+	}
+	for _, filename := range sp.docPkg.Filenames {
+		sp.Files = append(sp.Files, siteLink{
+			Name: filepath.Base(filename),
+			URL:  siteRel(sp.Path, siteSourcePath(pkg.ImportPath, filename))})
+	}
+
+	sp.Doc, sp.Examples = sp.html(sp.docPkg.Doc), sp.examples[``]
+	sp.index(pkg.Name, "package", ``, sp.docPkg.Doc)
+	sp.Consts = sp.values("const", sp.docPkg.Consts)
+	sp.Vars = sp.values("var", sp.docPkg.Vars)
+	for _, f := range docFuncs(sp.docPkg.Funcs) {
+		sp.Funcs = append(sp.Funcs, sp.fn(``, f))
+	}
+	for _, typ := range sp.docPkg.Types {
+		st := siteType{siteDecl: sp.decl(typ.Name, "type", typ.Name, typ.Decl, typ.Doc)}
+		st.Decls = append(st.Decls, sp.values("const", typ.Consts)...)
+		st.Decls = append(st.Decls, sp.values("var", typ.Vars)...)
+		for _, f := range typ.Funcs {
+			st.Decls = append(st.Decls, sp.fn(``, f))
+		}
+		for _, f := range typ.Methods {
+			st.Decls = append(st.Decls, sp.fn(typ.Name, f))
+		}
+		sp.Types = append(sp.Types, st)
+	}
+	return sp
+}
+
+func (sp *sitePackage) html(text string) template.HTML {
+	if len(text) == 0 {
+		return ``
+	}
+	return template.HTML(sp.printer.HTML(sp.docPkg.Parser().Parse(text)))
+}
+
+func (sp *sitePackage) index(name, kind, id, text string) {
+	url := sp.Path
+	if len(id) > 0 {
+		url += "#" + id
+	}
+	sp.search = append(sp.search, SearchEntry{
+		Name: name, Kind: kind, Package: sp.Pkg.ImportPath,
+		Synopsis: doc.Synopsis(text), URL: url})
+}
+
+func (sp *sitePackage) decl(id, kind, name string, node ast.Node, text string) siteDecl {
+	position := sp.fileSet.Position(node.Pos())
+	sp.index(name, kind, id, text)
+	return siteDecl{
+		ID:       id,
+		Name:     name,
+		Code:     nodeString(sp.fileSet, node),
+		Doc:      sp.html(text),
+		Examples: sp.examples[id],
+		Src: siteRel(sp.Path, siteSourcePath(sp.Pkg.ImportPath, position.Filename)) +
+			"#L" + strconv.Itoa(position.Line),
+	}
+}
+
+func (sp *sitePackage) values(kind string, values []*doc.Value) (out []siteDecl) {
+	for _, v := range values {
+		sd := sp.decl(v.Names[0], kind, strings.Join(v.Names, ", "), v.Decl, v.Doc)
+		for _, name := range v.Names[1:] {
+			sp.index(name, kind, v.Names[0], v.Doc)
+		}
+		out = append(out, sd)
+	}
+	return
+}
+
+func (sp *sitePackage) fn(recv string, f *doc.Func) siteDecl {
+	decl := *f.Decl
+	decl.Body, decl.Doc = nil, nil
+	id, kind := f.Name, "func"
+	if len(recv) > 0 {
+		id, kind = recv+"."+f.Name, "method"
+	}
+	return sp.decl(id, kind, id, &decl, f.Doc)
+}
+
+var siteTemplates = template.Must(template.New("site").Funcs(template.FuncMap{
+	"inc": func(i int) int { return i + 1 },
+}).Parse(`
+{{- define "header" -}}
+<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; max-width: 60em; margin: auto; padding: 1em; }
+pre { background: #f4f4f4; padding: 0.5em; overflow-x: auto; }
+.lines a { color: #999; text-decoration: none; display: inline-block; width: 4em; }
+</style>
+</head>
+<body>
+<nav><a href="{{.Root}}/index.html">Index</a></nav>
+{{- end -}}
+
+{{- define "footer" -}}
+</body>
+</html>
+{{ end -}}
+
+{{- define "examples" -}}
+{{- range . }}
+<details><summary>{{.Title}}</summary>
+{{.Doc}}<pre>{{.Code}}</pre>
+{{- if .Output}}
+<p>Output:</p>
+<pre>{{.Output}}</pre>
+{{- end}}
+</details>
+{{- end -}}
+{{- end -}}
+
+{{- define "decl" -}}
+<h3 id="{{.ID}}"><a href="{{.Src}}">{{.Name}}</a></h3>
+<pre>{{.Code}}</pre>
+{{.Doc}}{{template "examples" .Examples}}
+{{- end -}}
+
+{{- define "index" -}}
+{{template "header" .}}
+<h1>{{.Title}}</h1>
+<table>
+{{- range .Packages}}
+<tr><td><a href="{{.URL}}">{{.Name}}</a></td><td>{{.Synopsis}}</td></tr>
+{{- end}}
+</table>
+{{template "footer" .}}
+{{- end -}}
+
+{{- define "package" -}}
+{{template "header" .}}
+<h1>Package {{.Pkg.Name}}</h1>
+<p><code>import "{{.Pkg.ImportPath}}"</code></p>
+{{.Doc}}{{template "examples" .Examples}}
+<h2 id="pkg-files">Files</h2>
+<ul>
+{{- range .Files}}
+<li><a href="{{.URL}}">{{.Name}}</a></li>
+{{- end}}
+</ul>
+{{- if .Consts}}
+<h2 id="pkg-constants">Constants</h2>
+{{- range .Consts}}
+{{template "decl" .}}
+{{- end}}
+{{- end}}
+{{- if .Vars}}
+<h2 id="pkg-variables">Variables</h2>
+{{- range .Vars}}
+{{template "decl" .}}
+{{- end}}
+{{- end}}
+{{- if .Funcs}}
+<h2 id="pkg-functions">Functions</h2>
+{{- range .Funcs}}
+{{template "decl" .}}
+{{- end}}
+{{- end}}
+{{- if .Types}}
+<h2 id="pkg-types">Types</h2>
+{{- range .Types}}
+{{template "decl" .}}
+{{- range .Decls}}
+{{template "decl" .}}
+{{- end}}
+{{- end}}
+{{- end}}
+{{template "footer" .}}
+{{- end -}}
+
+{{- define "source" -}}
+{{template "header" .}}
+<h1>{{.Title}}</h1>
+<p>Package <a href="{{.Package.URL}}">{{.Package.Name}}</a></p>
+<pre class="lines">
+{{- range $i, $line := .Lines}}
+{{- $n := inc $i}}
+<span id="L{{$n}}"><a href="#L{{$n}}">{{$n}}</a>{{$line}}</span>
+{{- end}}
+</pre>
+{{template "footer" .}}
+{{- end -}}
+`))
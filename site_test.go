@@ -0,0 +1,70 @@
+package srcutil
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSite(t *testing.T) {
+	const unused = "github.com/cstockton/go-srcutil/testdata/unused/"
+	ctx := FromWorkDir()
+	pkgs, err := ctx.ImportAll(tPkg.ImportPath, unused+"lib")
+	tmust(t, err)
+	dir := t.TempDir()
+	tmust(t, NewSite("Test Site", pkgs...).Write(dir))
+
+	read := func(t *testing.T, name string) string {
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		tmust(t, err)
+		return string(data)
+	}
+	contains := func(t *testing.T, got string, exps ...string) {
+		for _, exp := range exps {
+			if !strings.Contains(got, exp) {
+				t.Errorf("exp page to contain:\n%v\ngot:\n%v", exp, got)
+			}
+		}
+	}
+	t.Run("Index", func(t *testing.T) {
+		contains(t, read(t, "index.html"),
+			"<title>Test Site</title>",
+			`<a href="pkg/`+tPkg.ImportPath+`/index.html">`+tPkg.ImportPath+`</a>`,
+			`<td>Package lib is used for testing srcutil.UnusedExports.</td>`)
+	})
+	t.Run("Package", func(t *testing.T) {
+		root := strings.Repeat("../", strings.Count(tPkg.ImportPath, "/")+2)
+		contains(t, read(t, "pkg/"+tPkg.ImportPath+"/index.html"),
+			`<nav><a href="`+root+`index.html">Index</a></nav>`,
+			`<h1>Package tpkg</h1>`,
+			`<h3 id="StringFunc"><a href="`+root+`src/`+tPkg.ImportPath+`/tpkg.go.html#L26">StringFunc</a></h3>`,
+			`<pre>func StringFunc(str string) string</pre>`,
+			`<h3 id="PublicStruct.MethodOne">`,
+			`<summary>Example</summary>`,
+			`<pre>fmt.Println(&#34;Example&#34;)`,
+			`<a href="`+root+`src/`+tPkg.ImportPath+`/tpkg_test.go.html">tpkg_test.go</a>`)
+	})
+	t.Run("Source", func(t *testing.T) {
+		contains(t, read(t, "src/"+tPkg.ImportPath+"/tpkg.go.html"),
+			`<span id="L8"><a href="#L8">8</a>package tpkg</span>`,
+			`<a href="../../../../../pkg/`+tPkg.ImportPath+`/index.html">`)
+	})
+	t.Run("Search", func(t *testing.T) {
+		var got []SearchEntry
+		tmust(t, json.Unmarshal([]byte(read(t, "search.json")), &got))
+		var found bool
+		for _, entry := range got {
+			if entry.Name == "PublicStruct.MethodOne" {
+				found = true
+				teq(t, SearchEntry{Name: "PublicStruct.MethodOne", Kind: "method",
+					Package:  tPkg.ImportPath,
+					Synopsis: "MethodOne rutrum convallis lorem lacus, eu fusce mi sapien vitae.",
+					URL:      "pkg/" + tPkg.ImportPath + "/index.html#PublicStruct.MethodOne"},
+					entry)
+			}
+		}
+		teq(t, true, found)
+	})
+}
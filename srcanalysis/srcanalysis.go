@@ -0,0 +1,422 @@
+// Package srcanalysis runs the analyzers of the golang.org/x/tools/go/analysis
+// framework over srcutil packages, and adapts checks written with srcutil into
+// analyzers so they may be used by any analysis driver such as go vet.
+package srcanalysis
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+
+	"github.com/cstockton/go-srcutil"
+	"golang.org/x/tools/go/analysis"
+)
+
+// Result is the outcome of running a single Analyzer on a single Package.
+type Result struct {
+	Package  *srcutil.Package
+	Analyzer *analysis.Analyzer
+
+	// Fset holds the positions of the Diagnostics.
+	Fset        *token.FileSet
+	Diagnostics []analysis.Diagnostic
+
+	// Result is the value returned by the Run func of the Analyzer, Err is the
+	// error it returned or the error of an Analyzer it requires.
+	Result interface{}
+	Err    error
+}
+
+// ToDiagnostics returns the Diagnostics as srcutil diagnostics, those without
+// a category are given the name of the Analyzer.
+func (r *Result) ToDiagnostics() []srcutil.Diagnostic {
+	out := make([]srcutil.Diagnostic, len(r.Diagnostics))
+	for i, d := range r.Diagnostics {
+		out[i] = srcutil.Diagnostic{Pos: r.Fset.Position(d.Pos),
+			Category: d.Category, Message: d.Message}
+		if len(d.Category) == 0 {
+			out[i].Category = r.Analyzer.Name
+		}
+	}
+	return out
+}
+
+// Run runs each analyzer and the analyzers they require on every package in
+// pkgs, returning a Result for each of the given analyzers and packages. The
+// packages are analyzed after those among pkgs they import so facts flow from
+// dependencies to their importers, facts are only available for dependencies
+// which are included within pkgs.
+//
+// An error is returned when the analyzers are invalid or a package could not
+// be type checked, the errors of analyzers are set on their Result.
+func Run(pkgs []*srcutil.Package, analyzers ...*analysis.Analyzer) ([]Result, error) {
+	if err := analysis.Validate(analyzers); err != nil {
+		return nil, err
+	}
+	facts := make(map[factKey]analysis.Fact)
+
+	var out []Result
+	for _, pkg := range depOrder(pkgs) {
+		u, err := newUnit(pkg, facts)
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range analyzers {
+			act := u.run(a)
+			out = append(out, Result{Package: pkg, Analyzer: a, Fset: u.fset,
+				Diagnostics: act.diagnostics, Result: act.result, Err: act.err})
+		}
+	}
+	return out, nil
+}
+
+// NewAnalyzer returns an Analyzer with the given name and doc which reports
+// the diagnostics returned by check. The package is imported from the
+// directory of the files being analyzed, external test packages are skipped
+// as they are not loaded by srcutil.
+func NewAnalyzer(
+	name, doc string,
+	check func(*srcutil.Package) ([]srcutil.Diagnostic, error),
+) *analysis.Analyzer {
+	return &analysis.Analyzer{
+		Name: name,
+		Doc:  doc,
+		Run: func(pass *analysis.Pass) (interface{}, error) {
+			if len(pass.Files) == 0 {
+				return nil, nil
+			}
+
+			// diagnostics are mapped back by file name and offset, all the files
+			// of a package are within the same directory.
+			files := make(map[string]*token.File)
+			for _, f := range pass.Files {
+				tf := pass.Fset.File(f.Pos())
+				files[filepath.Base(tf.Name())] = tf
+			}
+			dir := filepath.Dir(pass.Fset.File(pass.Files[0].Pos()).Name())
+			pkgs, err := srcutil.FromDir(dir).ImportAll(".")
+			if err != nil {
+				return nil, err
+			}
+			if pkgs[0].Name != pass.Pkg.Name() {
+				return nil, nil
+			}
+
+			diags, err := check(pkgs[0])
+			if err != nil {
+				return nil, err
+			}
+			for _, d := range diags {
+				tf, ok := files[filepath.Base(d.Pos.Filename)]
+				if !ok || d.Pos.Offset > tf.Size() {
+					continue
+				}
+				pass.Report(analysis.Diagnostic{Pos: tf.Pos(d.Pos.Offset),
+					Category: d.Category, Message: d.Message})
+			}
+			return nil, nil
+		},
+	}
+}
+
+// depOrder returns pkgs ordered so each package follows those among pkgs it
+// imports, otherwise retaining their order.
+func depOrder(pkgs []*srcutil.Package) []*srcutil.Package {
+	index := make(map[string]*srcutil.Package)
+	for _, pkg := range pkgs {
+		index[pkg.ImportPath] = pkg
+	}
+
+	var out []*srcutil.Package
+	seen := make(map[*srcutil.Package]bool)
+	var visit func(pkg *srcutil.Package)
+	visit = func(pkg *srcutil.Package) {
+		if seen[pkg] {
+			return
+		}
+		seen[pkg] = true
+		for _, imports := range [][]string{pkg.Imports, pkg.TestImports} {
+			for _, importPath := range imports {
+				if dep, ok := index[importPath]; ok {
+					visit(dep)
+				}
+			}
+		}
+		out = append(out, pkg)
+	}
+	for _, pkg := range pkgs {
+		visit(pkg)
+	}
+	return out
+}
+
+// factKey identifies a fact by the import path of its package and the type
+// of the fact, along with the position of its object for object facts.
+// Positions are used since each package is type checked separately, so the
+// objects of a dependency differ between the packages which import it.
+type factKey struct {
+	path string
+	obj  string
+	typ  reflect.Type
+}
+
+// unit is a single package being analyzed.
+type unit struct {
+	pkg      *srcutil.Package
+	fset     *token.FileSet
+	files    []*ast.File
+	info     *types.Info
+	typesPkg *types.Package
+	facts    map[factKey]analysis.Fact
+	actions  map[*analysis.Analyzer]*action
+	objects  map[string]types.Object
+}
+
+// action is the outcome of running an Analyzer on a unit.
+type action struct {
+	diagnostics []analysis.Diagnostic
+	result      interface{}
+	err         error
+}
+
+func newUnit(pkg *srcutil.Package, facts map[factKey]analysis.Fact) (*unit, error) {
+	tf, err := pkg.ToFiles()
+	if err != nil {
+		return nil, err
+	}
+	return &unit{pkg: pkg, fset: tf.FileSet, files: tf.Files, info: tf.Info,
+		typesPkg: tf.Types, facts: facts,
+		actions: make(map[*analysis.Analyzer]*action)}, nil
+}
+
+// run runs a after the analyzers it requires, at most once.
+func (u *unit) run(a *analysis.Analyzer) *action {
+	if act, ok := u.actions[a]; ok {
+		return act
+	}
+	act := new(action)
+	u.actions[a] = act
+
+	resultOf := make(map[*analysis.Analyzer]interface{})
+	for _, req := range a.Requires {
+		dep := u.run(req)
+		if dep.err != nil {
+			act.err = fmt.Errorf("required analyzer %s failed: %v", req.Name, dep.err)
+			return act
+		}
+		resultOf[req] = dep.result
+	}
+
+	pass := &analysis.Pass{
+		Analyzer:     a,
+		Fset:         u.fset,
+		Files:        u.files,
+		OtherFiles:   u.otherFiles(),
+		IgnoredFiles: u.ignoredFiles(),
+		Pkg:          u.typesPkg,
+		TypesInfo:    u.info,
+		TypesSizes:   types.SizesFor("gc", build.Default.GOARCH),
+		ResultOf:     resultOf,
+		ReadFile:     os.ReadFile,
+		Report: func(d analysis.Diagnostic) {
+			act.diagnostics = append(act.diagnostics, d)
+		},
+		ImportObjectFact: func(obj types.Object, fact analysis.Fact) bool {
+			return u.importFact(u.objectKey(obj, fact), fact)
+		},
+		ImportPackageFact: func(pkg *types.Package, fact analysis.Fact) bool {
+			return u.importFact(u.packageKey(pkg, fact), fact)
+		},
+		ExportObjectFact: func(obj types.Object, fact analysis.Fact) {
+			if obj.Pkg() != u.typesPkg {
+				panic(fmt.Sprintf("%s: exported fact for object %s of another package",
+					a.Name, obj))
+			}
+			u.exportFact(u.objectKey(obj, fact), fact)
+		},
+		ExportPackageFact: func(fact analysis.Fact) {
+			u.exportFact(u.packageKey(u.typesPkg, fact), fact)
+		},
+		AllObjectFacts: func() []analysis.ObjectFact {
+			return u.allObjectFacts(a)
+		},
+		AllPackageFacts: func() []analysis.PackageFact {
+			return u.allPackageFacts(a)
+		},
+	}
+	act.result, act.err = a.Run(pass)
+	return act
+}
+
+func (u *unit) otherFiles() (out []string) {
+	p := u.pkg
+	for _, names := range [][]string{p.CFiles, p.CXXFiles, p.MFiles, p.HFiles,
+		p.FFiles, p.SFiles, p.SwigFiles, p.SwigCXXFiles, p.SysoFiles} {
+		for _, name := range names {
+			out = append(out, filepath.Join(p.Dir, name))
+		}
+	}
+	return
+}
+
+func (u *unit) ignoredFiles() (out []string) {
+	p := u.pkg
+	for _, names := range [][]string{p.IgnoredGoFiles, p.IgnoredOtherFiles} {
+		for _, name := range names {
+			out = append(out, filepath.Join(p.Dir, name))
+		}
+	}
+	return
+}
+
+func (u *unit) objectKey(obj types.Object, fact analysis.Fact) factKey {
+	key := factKey{obj: posKey(u.fset, obj.Pos()), typ: reflect.TypeOf(fact)}
+	if obj.Pkg() != nil && obj.Pos().IsValid() {
+		key.path = obj.Pkg().Path()
+	}
+	return key
+}
+
+func (u *unit) packageKey(pkg *types.Package, fact analysis.Fact) factKey {
+	return factKey{path: pkg.Path(), typ: reflect.TypeOf(fact)}
+}
+
+func (u *unit) importFact(key factKey, fact analysis.Fact) bool {
+	if len(key.path) == 0 {
+		return false
+	}
+	found, ok := u.facts[key]
+	if ok {
+		reflect.ValueOf(fact).Elem().Set(reflect.ValueOf(found).Elem())
+	}
+	return ok
+}
+
+func (u *unit) exportFact(key factKey, fact analysis.Fact) {
+	if len(key.path) == 0 {
+		panic(fmt.Sprintf("exported fact %T for an object without a position", fact))
+	}
+	u.facts[key] = fact
+}
+
+// packages returns the package being analyzed and its transitive imports
+// keyed by import path.
+func (u *unit) packages() map[string]*types.Package {
+	out := make(map[string]*types.Package)
+	var visit func(pkg *types.Package)
+	visit = func(pkg *types.Package) {
+		if _, ok := out[pkg.Path()]; ok {
+			return
+		}
+		out[pkg.Path()] = pkg
+		for _, imp := range pkg.Imports() {
+			visit(imp)
+		}
+	}
+	visit(u.typesPkg)
+	return out
+}
+
+// objectIndex returns the objects facts may be exported for keyed by their
+// position, which are the objects defined within the package being analyzed
+// and the package level objects, methods and fields of its imports.
+func (u *unit) objectIndex() map[string]types.Object {
+	if u.objects != nil {
+		return u.objects
+	}
+	u.objects = make(map[string]types.Object)
+	add := func(obj types.Object) {
+		if obj != nil && obj.Pos().IsValid() {
+			u.objects[posKey(u.fset, obj.Pos())] = obj
+		}
+	}
+	for _, obj := range u.info.Defs {
+		add(obj)
+	}
+	for _, pkg := range u.packages() {
+		scope := pkg.Scope()
+		for _, name := range scope.Names() {
+			obj := scope.Lookup(name)
+			add(obj)
+
+			named, ok := obj.Type().(*types.Named)
+			if _, isType := obj.(*types.TypeName); !ok || !isType {
+				continue
+			}
+			for i := 0; i < named.NumMethods(); i++ {
+				add(named.Method(i))
+			}
+			switch t := named.Underlying().(type) {
+			case *types.Struct:
+				for i := 0; i < t.NumFields(); i++ {
+					add(t.Field(i))
+				}
+			case *types.Interface:
+				for i := 0; i < t.NumExplicitMethods(); i++ {
+					add(t.ExplicitMethod(i))
+				}
+			}
+		}
+	}
+	return u.objects
+}
+
+// factTypes returns the types of facts declared by a.
+func factTypes(a *analysis.Analyzer) map[reflect.Type]bool {
+	out := make(map[reflect.Type]bool)
+	for _, fact := range a.FactTypes {
+		out[reflect.TypeOf(fact)] = true
+	}
+	return out
+}
+
+func (u *unit) allObjectFacts(a *analysis.Analyzer) (out []analysis.ObjectFact) {
+	declared, index, pkgs := factTypes(a), u.objectIndex(), u.packages()
+	for key, fact := range u.facts {
+		if len(key.obj) == 0 || !declared[key.typ] || pkgs[key.path] == nil {
+			continue
+		}
+		if obj, ok := index[key.obj]; ok {
+			out = append(out, analysis.ObjectFact{Object: obj, Fact: fact})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := posKey(u.fset, out[i].Object.Pos()), posKey(u.fset, out[j].Object.Pos())
+		if a != b {
+			return a < b
+		}
+		return reflect.TypeOf(out[i].Fact).String() < reflect.TypeOf(out[j].Fact).String()
+	})
+	return
+}
+
+func (u *unit) allPackageFacts(a *analysis.Analyzer) (out []analysis.PackageFact) {
+	declared, pkgs := factTypes(a), u.packages()
+	for key, fact := range u.facts {
+		if len(key.obj) != 0 || !declared[key.typ] || pkgs[key.path] == nil {
+			continue
+		}
+		out = append(out, analysis.PackageFact{Package: pkgs[key.path], Fact: fact})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i].Package.Path(), out[j].Package.Path()
+		if a != b {
+			return a < b
+		}
+		return reflect.TypeOf(out[i].Fact).String() < reflect.TypeOf(out[j].Fact).String()
+	})
+	return
+}
+
+// posKey returns a key for pos which is the same across the file sets of
+// packages parsed from the same files.
+func posKey(fset *token.FileSet, pos token.Pos) string {
+	p := fset.Position(pos)
+	return fmt.Sprintf("%s:%d", p.Filename, p.Offset)
+}
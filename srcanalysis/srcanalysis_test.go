@@ -0,0 +1,100 @@
+package srcanalysis
+
+import (
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/cstockton/go-srcutil"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/printf"
+)
+
+const tpkg = "github.com/cstockton/go-srcutil/testdata/analysis/"
+
+func messages(t *testing.T, res Result) (out []string) {
+	if res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	for _, d := range res.ToDiagnostics() {
+		out = append(out, filepath.Base(d.Pos.Filename)+":"+
+			strconv.Itoa(d.Pos.Line)+": "+d.Message)
+	}
+	return
+}
+
+func TestRun(t *testing.T) {
+	// use is first to ensure wrap is analyzed before it
+	pkgs, err := srcutil.FromWorkDir().ImportAll(tpkg+"use", tpkg+"wrap")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("Facts", func(t *testing.T) {
+		res, err := Run(pkgs, printf.Analyzer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(res) != 2 {
+			t.Fatalf("exp 2 results; got %d", len(res))
+		}
+		if res[0].Package != pkgs[1] || res[1].Package != pkgs[0] {
+			t.Fatalf("exp wrap to be analyzed before use")
+		}
+		if got := messages(t, res[0]); len(got) != 0 {
+			t.Errorf("exp no diagnostics for wrap; got %q", got)
+		}
+
+		got := messages(t, res[1])
+		exp := []string{
+			`use.go:12: fmt.Printf format %d has arg "one" of wrong type string`,
+			"use.go:13: " + tpkg + "wrap.Logf format %s has arg 2 of wrong type int",
+		}
+		if strings.Join(got, "\n") != strings.Join(exp, "\n") {
+			t.Errorf("exp diagnostics:\n  %q\ngot:\n  %q", exp, got)
+		}
+	})
+	t.Run("WithoutDependency", func(t *testing.T) {
+		res, err := Run(pkgs[:1], printf.Analyzer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := messages(t, res[0])
+		if len(got) != 1 || !strings.Contains(got[0], "fmt.Printf") {
+			t.Errorf("exp only the fmt.Printf diagnostic; got %q", got)
+		}
+	})
+	t.Run("Invalid", func(t *testing.T) {
+		a := &analysis.Analyzer{Name: "not valid", Doc: "invalid",
+			Run: func(*analysis.Pass) (interface{}, error) { return nil, nil }}
+		if _, err := Run(pkgs, a); err == nil {
+			t.Errorf("exp error for invalid analyzer")
+		}
+	})
+}
+
+func TestNewAnalyzer(t *testing.T) {
+	pkgs, err := srcutil.FromWorkDir().ImportAll(tpkg + "wrap")
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := NewAnalyzer("examples", "reports exports without examples",
+		func(pkg *srcutil.Package) ([]srcutil.Diagnostic, error) {
+			docs := pkg.Docs()
+			return docs.MissingExamples(), nil
+		})
+
+	res, err := Run(pkgs, a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := messages(t, res[0])
+	exp := "wrap.go:7: exported func Logf has no examples"
+	if len(got) != 1 || got[0] != exp {
+		t.Errorf("exp diagnostics %q; got %q", []string{exp}, got)
+	}
+	if cat := res[0].ToDiagnostics()[0].Category; cat != "example" {
+		t.Errorf("exp category example; got %q", cat)
+	}
+}
@@ -18,6 +18,8 @@ import (
 	"go/parser"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 )
 
 var (
@@ -108,3 +110,111 @@ func (c *Context) Import(pkgName string) (*Package, error) {
 	}
 	return &Package{Package: *buildPkg}, nil
 }
+
+// ImportAll is like Import except it imports each of the given packages and
+// initializes them, returning the first error encountered.
+func (c *Context) ImportAll(pkgNames ...string) ([]*Package, error) {
+	pkgs := make([]*Package, len(pkgNames))
+	for i, pkgName := range pkgNames {
+		pkg, err := c.Import(pkgName)
+		if err != nil {
+			return nil, err
+		}
+		if err = pkg.init(); err != nil {
+			return nil, err
+		}
+		pkgs[i] = pkg
+	}
+	return pkgs, nil
+}
+
+// Match expands each of the given patterns into the import paths of the
+// packages they match, as described by "go help packages". Patterns without
+// a "..." wildcard are returned as given. Relative patterns such as "./..."
+// are matched against directories within SourceDir and return relative import
+// paths, while other patterns are matched against the source directories of
+// GOROOT and GOPATH. Directories named testdata or vendor and those beginning
+// with "." or "_" are ignored, as are directories without Go files.
+func (c *Context) Match(patterns ...string) ([]string, error) {
+	var out []string
+	for _, pattern := range patterns {
+		if !strings.Contains(pattern, "...") {
+			out = append(out, pattern)
+			continue
+		}
+		matches, err := c.matchPattern(pattern)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, matches...)
+	}
+	return out, nil
+}
+
+func (c *Context) matchPattern(pattern string) ([]string, error) {
+	var (
+		out   []string
+		match = matchPattern(pattern)
+		local = build.IsLocalImport(pattern)
+		roots = c.SrcDirs()
+	)
+	if local {
+		roots = []string{defaultToGetwd(c.SourceDir)}
+	}
+
+	// Only walk the directories beneath the pattern's fixed prefix.
+	prefix := pattern[:strings.Index(pattern, "...")]
+	if i := strings.LastIndex(prefix, "/"); i >= 0 {
+		prefix = prefix[:i]
+	} else {
+		prefix = ``
+	}
+	for _, root := range roots {
+		walkRoot := filepath.Join(root, filepath.FromSlash(prefix))
+		err := filepath.Walk(walkRoot, func(path string, fi os.FileInfo, err error) error {
+			if err != nil || !fi.IsDir() {
+				return nil
+			}
+			if name := fi.Name(); path != walkRoot && (name == "testdata" ||
+				name == "vendor" || strings.HasPrefix(name, ".") ||
+				strings.HasPrefix(name, "_")) {
+				return filepath.SkipDir
+			}
+			rel, err := filepath.Rel(root, path)
+			if err != nil {
+				return nil
+			}
+			importPath := filepath.ToSlash(rel)
+			if local {
+				importPath = "./" + importPath
+				if rel == "." {
+					importPath = "."
+				}
+			}
+			if !match(importPath) {
+				return nil
+			}
+			_, err = c.Context.ImportDir(path, build.ImportComment)
+			if _, ok := err.(*build.NoGoError); ok {
+				return nil
+			}
+			out = append(out, importPath)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// hasString reports whether v is within s.
+func hasString(s []string, v string) bool {
+	for i := range s {
+		if s[i] == v {
+			return true
+		}
+	}
+	return false
+}
@@ -123,3 +123,26 @@ func TestImport(t *testing.T) {
 		}
 	})
 }
+
+func TestMatch(t *testing.T) {
+	const deps = "github.com/cstockton/go-srcutil/testdata/deps"
+	ctx := FromDir(tPkg.Path)
+
+	t.Run("Local", func(t *testing.T) {
+		got, err := ctx.Match("./deps/...")
+		tmust(t, err)
+		teq(t, []string{"./deps/app", "./deps/cyca", "./deps/cycb", "./deps/domain",
+			"./deps/store"}, got)
+	})
+	t.Run("ImportPath", func(t *testing.T) {
+		got, err := ctx.Match(deps+"/c...", "reflect")
+		tmust(t, err)
+		teq(t, []string{deps + "/cyca", deps + "/cycb", "reflect"}, got)
+	})
+	t.Run("Standard", func(t *testing.T) {
+		got, err := FromStandard().Match("go/...")
+		tmust(t, err)
+		teq(t, true, hasString(got, "go/ast"))
+		teq(t, false, hasString(got, "go"))
+	})
+}
@@ -0,0 +1,68 @@
+package srcutil
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"go/types"
+	"strings"
+)
+
+// GenerateStub returns gofmt'd source for the package with the import path
+// dstPath containing a method with a body that panics for each method of the
+// interface iface, along with an import declaration for the packages the
+// methods refer to. The methods are declared for the receiver "receiverName
+// typeName", where typeName may be a pointer such as "*File". Types of the
+// destination package are unqualified while all others are qualified by their
+// package name, so the stub may be placed alongside iface or within any other
+// package. Doc comments of the interface methods are kept.
+func GenerateStub(dstPath string, iface MethodSet, receiverName, typeName string) ([]byte, error) {
+	if iface.Obj == nil {
+		return nil, fmt.Errorf("stub: MethodSet %q has no object", iface.Name)
+	}
+	named, ok := iface.Obj.Type().(*types.Named)
+	if !ok || !types.IsInterface(named) {
+		return nil, fmt.Errorf("stub: %v is not a named interface", iface.Name)
+	}
+	if named.TypeParams().Len() > 0 {
+		return nil, fmt.Errorf("stub: generic interface %v is not supported", iface.Name)
+	}
+
+	imports := NewImports(dstPath)
+	qual := imports.Qualifier()
+	ifaceName := iface.Name
+	if pkg := iface.Obj.Pkg(); pkg.Path() != dstPath {
+		ifaceName = pkg.Name() + "." + iface.Name
+	}
+
+	var body bytes.Buffer
+	for i, name := range iface.Names() {
+		m := iface.Methods[name]
+		if i > 0 {
+			body.WriteString("\n")
+		}
+		if !m.Exported() {
+			return nil, fmt.Errorf(
+				"stub: %v has unexported method %v which can not be implemented",
+				iface.Name, name)
+		}
+		if len(m.Doc) > 0 {
+			for _, line := range strings.Split(strings.TrimSpace(m.Doc), "\n") {
+				body.WriteString(strings.TrimSpace("// "+line) + "\n")
+			}
+		} else {
+			fmt.Fprintf(&body, "// %s implements %s.\n", name, ifaceName)
+		}
+		fmt.Fprintf(&body, "func (%s %s) %s", receiverName, typeName, name)
+		types.WriteSignature(&body, m.Signature, qual)
+		body.WriteString(" {\n\tpanic(\"not implemented\")\n}\n")
+	}
+
+	var src bytes.Buffer
+	if imports.Len() > 0 {
+		src.Write(imports.Decl())
+		src.WriteString("\n")
+	}
+	src.Write(body.Bytes())
+	return format.Source(src.Bytes())
+}
@@ -0,0 +1,79 @@
+package srcutil
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerateStub(t *testing.T) {
+	const stub = "github.com/cstockton/go-srcutil/testdata/stub"
+	ctx := FromWorkDir()
+	pkg, err := ctx.Import(stub)
+	tmust(t, err)
+
+	t.Run("Store", func(t *testing.T) {
+		ms, err := pkg.MethodSet("Store")
+		tmust(t, err)
+		src, err := GenerateStub("example.com/memstore", ms, "s", "*MemStore")
+		tmust(t, err)
+		exp := `import (
+	"context"
+	"github.com/cstockton/go-srcutil/testdata/stub"
+)
+
+// Close implements stub.Store.
+func (s *MemStore) Close() error {
+	panic("not implemented")
+}
+
+// Get returns the item for key.
+func (s *MemStore) Get(ctx context.Context, key string) (*stub.Item, error) {
+	panic("not implemented")
+}
+
+// Len returns the number of items.
+func (s *MemStore) Len() int {
+	panic("not implemented")
+}
+
+// Put stores each of the items.
+func (s *MemStore) Put(ctx context.Context, items ...*stub.Item) error {
+	panic("not implemented")
+}
+
+// Walk implements stub.Store.
+func (s *MemStore) Walk(func(*stub.Item) bool) {
+	panic("not implemented")
+}
+`
+		teq(t, exp, string(src))
+	})
+	t.Run("SamePackage", func(t *testing.T) {
+		ms, err := pkg.MethodSet("Store")
+		tmust(t, err)
+		src, err := GenerateStub(stub, ms, "s", "*MemStore")
+		tmust(t, err)
+		for _, exp := range []string{
+			"import (\n\t\"context\"\n)\n",
+			"// Close implements Store.\n",
+			"Get(ctx context.Context, key string) (*Item, error) {\n",
+			"func (s *MemStore) Walk(func(*Item) bool) {\n",
+		} {
+			if !strings.Contains(string(src), exp) {
+				t.Errorf("exp stub to contain:\n%v\ngot:\n%s", exp, src)
+			}
+		}
+	})
+	t.Run("Failure", func(t *testing.T) {
+		for _, name := range []string{"Item", "Generic", "Private"} {
+			ms, err := pkg.MethodSet(name)
+			tmust(t, err)
+			if _, err = GenerateStub(stub, ms, "s", "S"); err == nil {
+				t.Errorf("exp error for %v", name)
+			}
+		}
+		if _, err = GenerateStub(stub, MethodSet{}, "s", "S"); err == nil {
+			t.Error("exp error for empty MethodSet")
+		}
+	})
+}
@@ -0,0 +1,233 @@
+package srcutil
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/types"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Symbol is a single declaration within a symbol index. Symbols are created
+// for the consts, vars, funcs and types of a package, along with the fields
+// of its structs and the methods of its types and interfaces.
+type Symbol struct {
+	Name       string `json:"name"`
+	ImportPath string `json:"importPath"`
+
+	// Kind is one of "const", "var", "func", "type", "field" or "method".
+	Kind     string `json:"kind"`
+	Exported bool   `json:"exported"`
+
+	// Scope is the name of the type a field or method belongs to and
+	// ScopeKind is "struct", "interface" or "type" depending on its kind.
+	Scope     string `json:"scope,omitempty"`
+	ScopeKind string `json:"scopeKind,omitempty"`
+
+	// Signature is the signature of funcs and methods without the func
+	// keyword, I.E. "(r io.Reader) error".
+	Signature string `json:"signature,omitempty"`
+
+	// Type is the type of consts, vars and fields. For types it is "struct",
+	// "interface" or the underlying type.
+	Type string `json:"type,omitempty"`
+
+	// File is the absolute path of the file the symbol is declared in, Offset
+	// is the byte offset of the identifier within it.
+	File   string `json:"file"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+	Offset int    `json:"offset"`
+}
+
+// Symbols returns the symbols of this package ordered by position, including
+// unexported and test declarations. An error is returned when the package
+// could not be type checked.
+func (p *Package) Symbols() ([]Symbol, error) {
+	if err := p.init(); err != nil {
+		return nil, err
+	}
+	qual := types.RelativeTo(p.tc.typesPkg)
+	var out []Symbol
+	add := func(obj types.Object, kind string) *Symbol {
+		pos := p.tc.fileSet.Position(obj.Pos())
+		out = append(out, Symbol{
+			Name: obj.Name(), ImportPath: p.ImportPath, Kind: kind,
+			Exported: obj.Exported(), File: pos.Filename, Line: pos.Line,
+			Column: pos.Column, Offset: pos.Offset})
+		return &out[len(out)-1]
+	}
+	signature := func(obj types.Object) string {
+		return strings.TrimPrefix(types.TypeString(obj.Type(), qual), "func")
+	}
+
+	scope := p.tc.typesPkg.Scope()
+	for _, name := range scope.Names() {
+		switch obj := scope.Lookup(name).(type) {
+		case *types.Const:
+			add(obj, "const").Type = types.TypeString(obj.Type(), qual)
+		case *types.Var:
+			add(obj, "var").Type = types.TypeString(obj.Type(), qual)
+		case *types.Func:
+			add(obj, "func").Signature = signature(obj)
+		case *types.TypeName:
+			p.typeSymbols(obj, qual, add, signature)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Offset < b.Offset
+	})
+	return out, nil
+}
+
+func (p *Package) typeSymbols(
+	obj *types.TypeName, qual types.Qualifier,
+	add func(types.Object, string) *Symbol, signature func(types.Object) string,
+) {
+	under := obj.Type().Underlying()
+	scopeKind, typ := "type", types.TypeString(under, qual)
+	switch under.(type) {
+	case *types.Struct:
+		scopeKind, typ = "struct", "struct"
+	case *types.Interface:
+		scopeKind, typ = "interface", "interface"
+	}
+	add(obj, "type").Type = typ
+
+	switch under := under.(type) {
+	case *types.Struct:
+		for i := 0; i < under.NumFields(); i++ {
+			field := add(under.Field(i), "field")
+			field.Scope, field.ScopeKind = obj.Name(), scopeKind
+			field.Type = types.TypeString(under.Field(i).Type(), qual)
+		}
+	case *types.Interface:
+		for i := 0; i < under.NumExplicitMethods(); i++ {
+			m := add(under.ExplicitMethod(i), "method")
+			m.Scope, m.ScopeKind = obj.Name(), scopeKind
+			m.Signature = signature(under.ExplicitMethod(i))
+		}
+	}
+	if named, ok := obj.Type().(*types.Named); ok && !obj.IsAlias() {
+		for i := 0; i < named.NumMethods(); i++ {
+			m := add(named.Method(i), "method")
+			m.Scope, m.ScopeKind = obj.Name(), scopeKind
+			m.Signature = signature(named.Method(i))
+		}
+	}
+}
+
+// CollectSymbols returns the symbols of each of the given packages. The first
+// error encountered is returned.
+func CollectSymbols(pkgs []*Package) ([]Symbol, error) {
+	var out []Symbol
+	for _, pkg := range pkgs {
+		symbols, err := pkg.Symbols()
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, symbols...)
+	}
+	return out, nil
+}
+
+// WriteSymbols writes symbols to w as a JSON array.
+func WriteSymbols(w io.Writer, symbols []Symbol) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent(``, `  `)
+	return enc.Encode(symbols)
+}
+
+// WriteTags writes symbols to w as a sorted tags file in the extended format
+// of universal-ctags. File names are written relative to dir, which should be
+// the directory the tags file is written to. Each tag is addressed by line
+// number and has the extension fields "kind", "line", "access" and when
+// available the scope, "signature" and "typeref".
+func WriteTags(w io.Writer, dir string, symbols []Symbol) error {
+	lines := make([]string, 0, len(symbols))
+	for _, sym := range symbols {
+		access := "private"
+		if sym.Exported {
+			access = "public"
+		}
+		fields := []string{
+			sym.Name, tagsPath(dir, sym.File), fmt.Sprintf("%d;\"", sym.Line),
+			"kind:" + sym.Kind, fmt.Sprintf("line:%d", sym.Line)}
+		if len(sym.Scope) > 0 {
+			fields = append(fields, sym.ScopeKind+":"+sym.Scope)
+		}
+		if len(sym.Signature) > 0 {
+			fields = append(fields, "signature:"+sym.Signature)
+		}
+		if len(sym.Type) > 0 && sym.Kind != "type" {
+			fields = append(fields, "typeref:typename:"+sym.Type)
+		}
+		fields = append(fields, "access:"+access)
+		lines = append(lines, strings.Join(fields, "\t"))
+	}
+	sort.Strings(lines)
+
+	bw := bufio.NewWriter(w)
+	bw.WriteString("!_TAG_FILE_FORMAT\t2\t/extended format/\n")
+	bw.WriteString("!_TAG_FILE_SORTED\t1\t/0=unsorted, 1=sorted, 2=foldcase/\n")
+	bw.WriteString("!_TAG_PROGRAM_NAME\tsrcutil\t//\n")
+	bw.WriteString("!_TAG_PROGRAM_URL\thttps://github.com/cstockton/go-srcutil\t//\n")
+	for _, line := range lines {
+		bw.WriteString(line + "\n")
+	}
+	return bw.Flush()
+}
+
+// WriteETags writes symbols to w as an Emacs TAGS file. File names are
+// written relative to dir and each file is read to find the text preceding
+// each symbol.
+func WriteETags(w io.Writer, dir string, symbols []Symbol) error {
+	var files []string
+	byFile := make(map[string][]Symbol)
+	for _, sym := range symbols {
+		if _, ok := byFile[sym.File]; !ok {
+			files = append(files, sym.File)
+		}
+		byFile[sym.File] = append(byFile[sym.File], sym)
+	}
+
+	bw := bufio.NewWriter(w)
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+		var buf bytes.Buffer
+		for _, sym := range byFile[file] {
+			end := sym.Offset + len(sym.Name)
+			if end > len(src) {
+				return fmt.Errorf("symbol %s is beyond the end of %s", sym.Name, file)
+			}
+			start := bytes.LastIndexByte(src[:sym.Offset], '\n') + 1
+			fmt.Fprintf(&buf, "%s\x7f%s\x01%d,%d\n",
+				src[start:end], sym.Name, sym.Line, start)
+		}
+		fmt.Fprintf(bw, "\x0c\n%s,%d\n", tagsPath(dir, file), buf.Len())
+		bw.Write(buf.Bytes())
+	}
+	return bw.Flush()
+}
+
+func tagsPath(dir, path string) string {
+	if len(dir) == 0 {
+		return path
+	}
+	if rel, err := filepath.Rel(dir, path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
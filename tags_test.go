@@ -0,0 +1,68 @@
+package srcutil
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSymbols(t *testing.T) {
+	const tags = "github.com/cstockton/go-srcutil/testdata/tags"
+	ctx := FromWorkDir()
+	pkg, err := ctx.Import(tags)
+	tmust(t, err)
+	symbols, err := pkg.Symbols()
+	tmust(t, err)
+
+	t.Run("Symbols", func(t *testing.T) {
+		var got []string
+		for _, sym := range symbols {
+			teq(t, tags, sym.ImportPath)
+			teq(t, "tags.go", filepath.Base(sym.File))
+			got = append(got, fmt.Sprintf("%d:%d %s %s %s:%s %q %q %v",
+				sym.Line, sym.Column, sym.Kind, sym.Name, sym.ScopeKind, sym.Scope,
+				sym.Signature, sym.Type, sym.Exported))
+		}
+		teq(t, []string{
+			`7:7 const Max : "" "untyped int" true`,
+			`9:5 var count : "" "int" false`,
+			`12:6 type Reader : "" "interface" true`,
+			`14:2 method Read interface:Reader "(p []byte) (n int, err error)" "" true`,
+			`18:6 type File : "" "struct" true`,
+			`19:2 field Name struct:File "" "string" true`,
+			`20:5 field Reader struct:File "" "io.Reader" true`,
+			`24:16 method Close struct:File "() error" "" true`,
+			`27:6 func Open : "(name string) (*File, error)" "" true`,
+			`30:6 type Mode : "" "uint32" true`,
+		}, got)
+	})
+	t.Run("WriteSymbols", func(t *testing.T) {
+		var buf bytes.Buffer
+		tmust(t, WriteSymbols(&buf, symbols))
+		var got []Symbol
+		tmust(t, json.Unmarshal(buf.Bytes(), &got))
+		teq(t, symbols, got)
+	})
+	t.Run("WriteTags", func(t *testing.T) {
+		var buf bytes.Buffer
+		tmust(t, WriteTags(&buf, pkg.Dir, symbols))
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		teq(t, 4+len(symbols), len(lines))
+		teq(t, "!_TAG_FILE_FORMAT\t2\t/extended format/", lines[0])
+		teq(t, "Close\ttags.go\t24;\"\tkind:method\tline:24\tstruct:File\t"+
+			"signature:() error\taccess:public", lines[4])
+		teq(t, "Max\ttags.go\t7;\"\tkind:const\tline:7\t"+
+			"typeref:typename:untyped int\taccess:public", lines[6])
+		teq(t, "count\ttags.go\t9;\"\tkind:var\tline:9\t"+
+			"typeref:typename:int\taccess:private", lines[len(lines)-1])
+	})
+	t.Run("WriteETags", func(t *testing.T) {
+		var buf bytes.Buffer
+		tmust(t, WriteETags(&buf, pkg.Dir, symbols[:2]))
+		body := "const Max\x7fMax\x017,99\nvar count\x7fcount\x019,115\n"
+		teq(t, fmt.Sprintf("\x0c\ntags.go,%d\n%s", len(body), body), buf.String())
+	})
+}
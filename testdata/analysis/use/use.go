@@ -0,0 +1,15 @@
+// Package use is used for testing srcanalysis.
+package use
+
+import (
+	"fmt"
+
+	"github.com/cstockton/go-srcutil/testdata/analysis/wrap"
+)
+
+// Run reports mistakes to the printf analyzer.
+func Run() {
+	fmt.Printf("%d\n", "one")
+	wrap.Logf("%s", 2)
+	wrap.Logf("%d", 3)
+}
@@ -0,0 +1,11 @@
+// Package wrap is used for testing srcanalysis.
+package wrap
+
+import "fmt"
+
+// Logf prints a formatted message.
+func Logf(format string, args ...interface{}) {
+	fmt.Printf(format, args...)
+}
+
+var Undocumented = 1
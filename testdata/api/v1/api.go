@@ -0,0 +1,51 @@
+// Package api is used for testing srcutil.DiffAPI, this is the old version.
+package api
+
+import "io"
+
+// Const will have its value changed.
+const Const = 1
+
+// Removed will be removed.
+const Removed = "removed"
+
+// Var will have its type changed.
+var Var int
+
+// Func will have its signature changed.
+func Func(a int, b string) error { return nil }
+
+// Same will not change, only its parameter names.
+func Same(r io.Reader, n ...int) (int, error) { return 0, nil }
+
+// Struct will have fields and methods changed.
+type Struct struct {
+	Name    string
+	Removed int
+	Changed int
+	hidden  int
+}
+
+// Keep will not change.
+func (s Struct) Keep() {}
+
+// Moved will move to a pointer receiver.
+func (s Struct) Moved() {}
+
+// Gone will be removed.
+func (s *Struct) Gone() {}
+
+// Iface will gain a method.
+type Iface interface {
+	io.Reader
+	Close() error
+}
+
+// Number will change its underlying type.
+type Number int
+
+// Alias will not change.
+type Alias = Struct
+
+// Generic will not change.
+type Generic[T comparable] struct{ V T }
@@ -0,0 +1,54 @@
+// Package api is used for testing srcutil.DiffAPI, this is the new version.
+package api
+
+import "io"
+
+// Const will have its value changed.
+const Const = 2
+
+// Added was added.
+const Added = "added"
+
+// Var will have its type changed.
+var Var string
+
+// Func will have its signature changed.
+func Func(a int, b string, c bool) error { return nil }
+
+// Same will not change, only its parameter names.
+func Same(reader io.Reader, nums ...int) (int, error) { return 0, nil }
+
+// AddedFunc was added.
+func AddedFunc() {}
+
+// Struct will have fields and methods changed.
+type Struct struct {
+	Name    string
+	Changed string
+	Added   bool
+}
+
+// Keep will not change.
+func (s Struct) Keep() {}
+
+// Moved will move to a pointer receiver.
+func (s *Struct) Moved() {}
+
+// Added2 was added.
+func (s Struct) Added2() {}
+
+// Iface will gain a method.
+type Iface interface {
+	io.Reader
+	Close() error
+	Flush() error
+}
+
+// Number will change its underlying type.
+type Number float64
+
+// Alias will not change.
+type Alias = Struct
+
+// Generic will not change.
+type Generic[T comparable] struct{ V T }
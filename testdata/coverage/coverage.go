@@ -0,0 +1,34 @@
+// This package is used for testing srcutil.Docs.Coverage.
+package coverage
+
+// Documented is documented.
+func Documented() {}
+
+func Undocumented() {}
+
+// returns the wrong name.
+func WrongName() {}
+
+// A Widget is documented with an article.
+type Widget struct {
+	// Name is documented.
+	Name string
+	Size int
+	size int
+}
+
+// Documented is documented.
+func (w Widget) Documented() {}
+
+func (w *Widget) Undocumented() {}
+
+// Grouped constants only need a group comment.
+const (
+	GroupOne = 1
+	GroupTwo = 2
+)
+
+// the Single var is documented with the wrong name.
+var Single = 1
+
+var Bare = 2
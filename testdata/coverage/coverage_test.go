@@ -0,0 +1,5 @@
+package coverage
+
+func ExportedTestHelper() {}
+
+func (w Widget) Helper() {}
@@ -0,0 +1,40 @@
+// Package old is used for testing srcutil deprecations.
+//
+// Deprecated: use package user instead.
+package old
+
+// Current is not deprecated.
+func Current() {}
+
+// Legacy is deprecated.
+//
+// Deprecated: Use Current
+// instead.
+func Legacy() {}
+
+// Config is deprecated.
+//
+// Deprecated: Use Options.
+type Config struct {
+	// Name is deprecated.
+	//
+	// Deprecated: Use Label.
+	Name  string
+	Label string
+}
+
+// Run runs.
+//
+// Deprecated: Use Start.
+func (c *Config) Run() {}
+
+// Start starts.
+func (c *Config) Start() {}
+
+// Default is deprecated.
+//
+// Deprecated: Use Current.
+var Default = Config{}
+
+// Mentions Deprecated: but not at the start of a paragraph.
+const Mentions = 1
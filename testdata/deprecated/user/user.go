@@ -0,0 +1,14 @@
+// Package user is used for testing srcutil deprecations.
+package user
+
+import "github.com/cstockton/go-srcutil/testdata/deprecated/old"
+
+// Use uses deprecated identifiers of package old.
+func Use() string {
+	old.Legacy()
+	old.Current()
+	c := old.Config{Name: "name"}
+	c.Run()
+	c.Start()
+	return old.Default.Name
+}
@@ -0,0 +1,10 @@
+// Package app is used for testing the srcutil DepGraph.
+package app
+
+import (
+	"github.com/cstockton/go-srcutil/testdata/deps/domain"
+	"github.com/cstockton/go-srcutil/testdata/deps/store"
+)
+
+// Run wires the domain to the store.
+func Run() string { return domain.Name(store.Open()) }
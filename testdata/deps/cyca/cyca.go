@@ -0,0 +1,8 @@
+// Package cyca is used for testing the srcutil DepGraph, it forms an import
+// cycle with package cycb.
+package cyca
+
+import "github.com/cstockton/go-srcutil/testdata/deps/cycb"
+
+// A returns B.
+func A() int { return cycb.B() }
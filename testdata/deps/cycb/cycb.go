@@ -0,0 +1,8 @@
+// Package cycb is used for testing the srcutil DepGraph, it forms an import
+// cycle with package cyca.
+package cycb
+
+import "github.com/cstockton/go-srcutil/testdata/deps/cyca"
+
+// B returns A.
+func B() int { return cyca.A() }
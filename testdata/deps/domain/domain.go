@@ -0,0 +1,8 @@
+// Package domain is used for testing the srcutil DepGraph, it imports net/http
+// to violate a layering rule.
+package domain
+
+import "net/http"
+
+// Name returns the name of s.
+func Name(s string) string { return http.CanonicalHeaderKey(s) }
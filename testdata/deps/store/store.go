@@ -0,0 +1,7 @@
+// Package store is used for testing the srcutil DepGraph.
+package store
+
+import "strings"
+
+// Open returns a store name.
+func Open() string { return strings.ToLower("Store") }
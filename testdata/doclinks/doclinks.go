@@ -0,0 +1,28 @@
+// Package doclinks is used for testing srcutil doc link resolution, it links
+// to [Good] and [io.Reader].
+//
+// # Heading
+//
+// A list of links:
+//   - [Good.Method] and [Good.Field]
+//   - [strings.Builder] which is not imported
+//   - [fmt]
+//
+// A code block:
+//
+//	var r io.Reader
+package doclinks
+
+import "io"
+
+// Good links to [Missing], [Good.Missing] and [io.Missing].
+type Good struct {
+	// Field links to [Good].
+	Field io.Reader
+}
+
+// Method links to [Nope] within a [broken link](https://example.com).
+func (g Good) Method() {}
+
+// Value is not a link, a[i] or [lower].
+var Value = 1
@@ -0,0 +1,19 @@
+// Package editor is used for testing the srcutil Editor.
+package editor
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Greeting is the default greeting.
+const Greeting = "hello" // not "hi"
+
+// Greet returns a greeting for name.
+func Greet(name string) string {
+	// keep this comment
+	return fmt.Sprintf("%s %s", Greeting, strings.TrimSpace(name))
+}
+
+// Unused is not used.
+func Unused() {}
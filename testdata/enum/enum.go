@@ -0,0 +1,30 @@
+// Package enum is used for testing srcutil enum generation.
+package enum
+
+// Color is a color.
+type Color uint8
+
+const (
+	// ColorRed is the color red.
+	ColorRed   Color = iota + 1
+	ColorGreen       // the color green
+	ColorBlue
+
+	// ColorDefault is the default color.
+	ColorDefault = ColorRed
+)
+
+// Plain is not an enum.
+type Plain string
+
+// Empty has no constants.
+type Empty int
+
+// Shade has constants with the same string once "Shade" is trimmed.
+type Shade int
+
+// Shades.
+const (
+	ShadeDark Shade = iota
+	Dark
+)
@@ -0,0 +1,16 @@
+// Package examples is used for testing srcutil example programs.
+package examples
+
+// Greet returns a greeting for name.
+func Greet(name string) string { return "Hello, " + name }
+
+func internal() string { return "internal" }
+
+// Counter counts.
+type Counter struct{ n int }
+
+// Inc increments the counter.
+func (c *Counter) Inc() int { c.n++; return c.n }
+
+// Reset resets the counter.
+func (c *Counter) Reset() { c.n = 0 }
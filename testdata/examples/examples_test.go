@@ -0,0 +1,14 @@
+package examples
+
+import "fmt"
+
+func Example_internal() {
+	fmt.Println(internal())
+	// Output: internal
+}
+
+func ExampleCounter_Inc() {
+	var c Counter
+	fmt.Println(c.Inc())
+	// Output: 1
+}
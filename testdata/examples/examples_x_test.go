@@ -0,0 +1,37 @@
+package examples_test
+
+import (
+	"fmt"
+
+	"github.com/cstockton/go-srcutil/testdata/examples"
+)
+
+func ExampleGreet() {
+	fmt.Println(examples.Greet("gopher"))
+	// Output: Hello, gopher
+}
+
+func ExampleGreet_mismatch() {
+	fmt.Println(examples.Greet("gopher"))
+	fmt.Println("same")
+	// Output:
+	// Hello, world
+	// same
+}
+
+func ExampleGreet_unordered() {
+	fmt.Println("b")
+	fmt.Println("a")
+	// Unordered output:
+	// a
+	// b
+}
+
+func ExampleGreet_noOutput() {
+	fmt.Println(examples.Greet("gopher"))
+}
+
+func ExampleGreet_broken() {
+	fmt.Println(examples.Greet(1))
+	// Output: Hello, 1
+}
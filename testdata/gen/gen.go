@@ -0,0 +1,37 @@
+// Package gen is used for testing srcutil.Generate.
+package gen
+
+import (
+	"io"
+	"time"
+)
+
+// Version is the version.
+const Version = "1.0"
+
+// User is a user.
+type User struct {
+	// ID is the id of the user.
+	ID      int64     `json:"id" db:"user_id,pk"`
+	Name    string    `json:"name,omitempty"`
+	Created time.Time `json:"-"`
+	Avatar  io.Reader
+	io.Closer
+	note string
+}
+
+// Greet greets the user.
+func (u *User) Greet(greeting string) string { return greeting + " " + u.Name }
+
+// Store stores users.
+type Store interface {
+	// Get returns the user for id.
+	Get(id int64) (*User, error)
+	Put(users ...*User) error
+}
+
+// Point is an array.
+type Point [2]float64
+
+// Open opens a store.
+func Open(name string, timeout time.Duration) (Store, error) { return nil, nil }
@@ -0,0 +1,9 @@
+// Package generic is used for testing srcutil.Generate with type parameters.
+package generic
+
+// Pair is a pair of values.
+type Pair[K comparable, V any] struct {
+	Key   K
+	Value V
+	Count int
+}
@@ -0,0 +1,35 @@
+// Package lint is used for testing the lint rules.
+package lint
+
+// Counter counts.
+type Counter struct {
+	n int
+}
+
+// Get returns the count.
+func (c Counter) Get() int {
+	return c.n
+}
+
+// Add adds i to the count.
+func (counter *Counter) Add(i int) {
+	counter.n += i
+}
+
+// Reset sets the count to zero.
+func (c *Counter) Reset() {
+	c.n = 0
+}
+
+type Undocumented int
+
+// New returns an unexported type.
+func New() []*impl {
+	return nil
+}
+
+type impl struct{}
+
+func (i impl) value() {}
+
+func (i *impl) pointer() {}
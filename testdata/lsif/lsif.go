@@ -0,0 +1,21 @@
+// Package lsif is used for testing srcutil LSIF positions.
+package lsif
+
+import (
+	"go/token"
+	"strings"
+)
+
+// Größe is a size.
+var Größe = 1
+
+var Use = /* π𝄞 */ Größe
+
+// Upper and Lower refer to objects which may be imported without positions.
+var Upper, Lower = strings.ToUpper, strings.ToLower
+
+// Len and Line refer to a method and field of imported types.
+var (
+	Len  = new(strings.Builder).Len
+	Line = token.Position{}.Line
+)
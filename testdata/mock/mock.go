@@ -0,0 +1,13 @@
+// Package mock is used for testing srcutil mock generation.
+package mock
+
+import "sync"
+
+// Collide has methods and parameters colliding with the generated names.
+type Collide interface {
+	Calls() int
+	AssertCalls(n int)
+	Get(m, r0 string, sync sync.Locker, append int) (string, error)
+	GetCalls() []string
+	GetFunc()
+}
@@ -0,0 +1,36 @@
+// Package notes is used for testing srcutil notes.
+package notes
+
+// TODO(cstockton): Package level note
+// spanning two lines.
+// BUG(chris): Second note in the same group.
+
+// Store stores values.
+//
+// FIXME(chris): Store is not safe for concurrent use.
+type Store struct {
+	m map[string]string
+}
+
+// Get returns the value for key.
+func (s *Store) Get(key string) string {
+	// TODO: handle missing keys.
+	return s.m[key]
+}
+
+/*
+Put sets the value for key.
+
+HACK(cstockton): block comment note.
+*/
+func (s *Store) Put(key, value string) {
+	// NOTE: this is not a default marker.
+	// TODO(): empty uid is not a note.
+	s.m[key] = value
+}
+
+const (
+	// XXX: bare note on a grouped const.
+	A = 1
+	B = 2
+)
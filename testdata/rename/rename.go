@@ -0,0 +1,41 @@
+// Package rename is used for testing srcutil.Package.Rename.
+package rename
+
+import "strings"
+
+// Greeting is the default greeting.
+const Greeting = "hello"
+
+// Greet returns a greeting for name.
+func Greet(name string) string {
+	msg := Greeting + " " + name
+	if len(msg) > 10 {
+		trimmed := strings.TrimSpace(msg)
+		return trimmed + msg
+	}
+	return msg
+}
+
+// Namer returns names.
+type Namer interface {
+	Name() string
+}
+
+// Person is a person.
+type Person struct {
+	First, Last string
+}
+
+// Name implements Namer.
+func (p *Person) Name() string { return p.First + " " + p.Last }
+
+// Full returns the full name.
+func (p *Person) Full() string { return p.Name() }
+
+// Employee embeds a Person.
+type Employee struct {
+	Person
+	ID int
+}
+
+func helper() string { return Greet("x") }
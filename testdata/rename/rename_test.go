@@ -0,0 +1,13 @@
+package rename_test
+
+import (
+	"testing"
+
+	"github.com/cstockton/go-srcutil/testdata/rename"
+)
+
+func TestGreet(t *testing.T) {
+	if got := rename.Greet("x"); len(got) == 0 {
+		t.Fatal("exp greeting")
+	}
+}
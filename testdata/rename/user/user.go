@@ -0,0 +1,17 @@
+// Package user is used for testing srcutil.Package.Rename.
+package user
+
+import "github.com/cstockton/go-srcutil/testdata/rename"
+
+// Hello greets a person.
+func Hello(p *rename.Person) string {
+	return rename.Greet(p.First)
+}
+
+// Bot is a Namer.
+type Bot struct{}
+
+// Name implements rename.Namer.
+func (Bot) Name() string { return "bot" }
+
+var _ rename.Namer = Bot{}
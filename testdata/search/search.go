@@ -0,0 +1,29 @@
+// Package search is used for testing srcutil.Pattern.
+package search
+
+import (
+	"bytes"
+	"io"
+	"os"
+	str "strings"
+)
+
+type closer struct{}
+
+func (closer) Close() error { return nil }
+
+// Run closes things.
+func Run(f *os.File, r io.Reader) (int, error) {
+	var buf bytes.Buffer
+	c := closer{}
+	f.Close()
+	c.Close()
+	if len(str.TrimSpace("a")) == len(str.TrimSpace("a")) {
+		buf.WriteString("x")
+	}
+	n := 2 * add(f.Name(), "y")
+	_ = n == n
+	return len("a"+"b") * len(buf.String()), nil
+}
+
+func add(a, b string) int { return len(a + b) }
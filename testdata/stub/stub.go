@@ -0,0 +1,43 @@
+// Package stub is used for testing srcutil generators.
+package stub
+
+import (
+	"context"
+	"io"
+)
+
+// Item is stored within a Store.
+type Item struct {
+	Key  string
+	Body io.Reader
+}
+
+// Store stores items.
+type Store interface {
+	io.Closer
+
+	// Get returns the item for key.
+	Get(ctx context.Context, key string) (*Item, error)
+
+	// Put stores each of the items.
+	Put(ctx context.Context, items ...*Item) error
+
+	// Len returns the number of items.
+	Len() int
+	Walk(func(*Item) bool)
+}
+
+// Generic is a generic interface.
+type Generic[T any] interface {
+	Get() T
+}
+
+type private interface {
+	get() string
+}
+
+// Private has an unexported method.
+type Private interface {
+	private
+	Get() string
+}
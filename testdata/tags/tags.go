@@ -0,0 +1,30 @@
+// Package tags is used for testing srcutil symbols.
+package tags
+
+import "io"
+
+// Max is a const.
+const Max = 10
+
+var count int
+
+// Reader reads.
+type Reader interface {
+	io.Closer
+	Read(p []byte) (n int, err error)
+}
+
+// File is a file.
+type File struct {
+	Name string
+	io.Reader
+}
+
+// Close closes the file.
+func (f *File) Close() error { return nil }
+
+// Open opens a file.
+func Open(name string) (*File, error) { return &File{Name: name}, nil }
+
+// Mode is a defined type.
+type Mode uint32
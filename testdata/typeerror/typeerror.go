@@ -0,0 +1,13 @@
+// Package typeerror is used for testing packages which fail to type check.
+package typeerror
+
+// Broken returns a string as an int.
+func Broken() int {
+	return "broken"
+}
+
+// Color is an enum.
+type Color int
+
+// Red is a Color.
+const Red Color = 0
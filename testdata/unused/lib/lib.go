@@ -0,0 +1,32 @@
+// Package lib is used for testing srcutil.UnusedExports.
+package lib
+
+// Used is called by package user.
+func Used() int { return used() }
+
+// Unused is never called outside of this package.
+func Unused() int { return used() }
+
+// UsedInTest is only called from the tests of package user.
+func UsedInTest() int { return used() }
+
+// Type is used by package user.
+type Type struct{ Field int }
+
+// Unused shares its name with the package level func, using it does not use
+// the func.
+func (Type) Unused() {}
+
+// UnusedType is never used.
+type UnusedType struct{}
+
+// Const is used by package user.
+const Const = 1
+
+// UnusedConst is only used within this package.
+const UnusedConst = 2
+
+// Var is only used from the external tests of package user.
+var Var = UnusedConst
+
+func used() int { return 0 }
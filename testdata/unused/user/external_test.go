@@ -0,0 +1,12 @@
+package user_test
+
+import (
+	"testing"
+
+	"github.com/cstockton/go-srcutil/testdata/unused/lib"
+	"github.com/cstockton/go-srcutil/testdata/unused/user"
+)
+
+func TestExternal(t *testing.T) {
+	_, _ = user.Use(), lib.Var
+}
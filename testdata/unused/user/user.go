@@ -0,0 +1,11 @@
+// Package user is used for testing srcutil.UnusedExports.
+package user
+
+import "github.com/cstockton/go-srcutil/testdata/unused/lib"
+
+// Use uses package lib.
+func Use() lib.Type {
+	t := lib.Type{Field: lib.Used() + lib.Const}
+	t.Unused()
+	return t
+}
@@ -0,0 +1,14 @@
+package user
+
+import (
+	"testing"
+
+	"github.com/cstockton/go-srcutil/testdata/unused/lib"
+)
+
+// ExportedTestHelper is declared within a test file so it is never reported.
+func ExportedTestHelper() {}
+
+func TestUse(t *testing.T) {
+	lib.UsedInTest()
+}
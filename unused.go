@@ -0,0 +1,113 @@
+package srcutil
+
+import (
+	"fmt"
+	"go/token"
+	"go/types"
+	"sort"
+	"strings"
+)
+
+// Unused is an exported identifier that is never referenced outside of the
+// package that declares it.
+type Unused struct {
+	Package  *Package
+	Obj      types.Object
+	Position token.Position
+}
+
+// String implements fmt.Stringer.
+func (u Unused) String() string {
+	return fmt.Sprintf("%v: %v %v.%v is unused outside its package",
+		u.Position, objKind(u.Obj), u.Package.Name, u.Obj.Name())
+}
+
+// UnusedExports returns the exported package level funcs, types, consts and
+// vars of the given packages which are not referenced by any of the other
+// given packages. References from _test.go files, including those of external
+// _test packages, are only counted when tests is true. Identifiers declared
+// within _test.go files are never reported. Results are ordered by position.
+//
+// Only references between the given packages are considered, so you should
+// provide every package that may use the API you are interested in. The first
+// error encountered type checking the packages is returned.
+func UnusedExports(pkgs []*Package, tests bool) ([]Unused, error) {
+	used := make(map[string]bool)
+	for _, pkg := range pkgs {
+		if err := pkg.init(); err != nil {
+			return nil, err
+		}
+		infos := []*types.Info{pkg.tc.typesInfo}
+		if tests {
+			info, err := pkg.xtestInfo()
+			if err != nil {
+				return nil, err
+			}
+			if info != nil {
+				infos = append(infos, info)
+			}
+		}
+		for _, info := range infos {
+			for ident, obj := range info.Uses {
+				if obj.Pkg() == nil || obj.Pkg() == pkg.tc.typesPkg {
+					continue
+				}
+				if obj.Parent() != obj.Pkg().Scope() {
+					continue // fields and methods
+				}
+				if !tests && isTestFile(pkg.tc.fileSet.Position(ident.Pos())) {
+					continue
+				}
+				used[obj.Pkg().Path()+"."+obj.Name()] = true
+			}
+		}
+	}
+
+	var out []Unused
+	for _, pkg := range pkgs {
+		scope := pkg.tc.typesPkg.Scope()
+		for _, name := range scope.Names() {
+			obj := scope.Lookup(name)
+			if !obj.Exported() || isTest(name, "Test") || isTest(name, "Example") {
+				continue
+			}
+			pos := pkg.tc.fileSet.Position(obj.Pos())
+			if isTestFile(pos) || used[pkg.ImportPath+"."+name] {
+				continue
+			}
+			out = append(out, Unused{Package: pkg, Obj: obj, Position: pos})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i].Position, out[j].Position
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		return a.Offset < b.Offset
+	})
+	return out, nil
+}
+
+func isTestFile(pos token.Position) bool {
+	return strings.HasSuffix(pos.Filename, "_test.go")
+}
+
+func objKind(obj types.Object) string {
+	switch obj := obj.(type) {
+	case *types.Func:
+		if obj.Type().(*types.Signature).Recv() != nil {
+			return "method"
+		}
+		return "func"
+	case *types.TypeName:
+		return "type"
+	case *types.Const:
+		return "const"
+	case *types.Var:
+		if obj.IsField() {
+			return "field"
+		}
+		return "var"
+	}
+	return "object"
+}
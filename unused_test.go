@@ -0,0 +1,44 @@
+package srcutil
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnusedExports(t *testing.T) {
+	const unused = "github.com/cstockton/go-srcutil/testdata/unused/"
+	ctx := FromWorkDir()
+	pkgs, err := ctx.ImportAll(unused+"lib", unused+"user")
+	tmust(t, err)
+
+	names := func(s []Unused) (out []string) {
+		for _, u := range s {
+			out = append(out, u.Obj.Name())
+		}
+		return
+	}
+	t.Run("WithoutTests", func(t *testing.T) {
+		got, err := UnusedExports(pkgs, false)
+		tmust(t, err)
+		teq(t, []string{"Unused", "UsedInTest", "UnusedType", "UnusedConst", "Var", "Use"},
+			names(got))
+		teq(t, "lib.go", got[0].Position.Filename[len(got[0].Position.Filename)-6:])
+		teq(t, 8, got[0].Position.Line)
+		teq(t, true, strings.HasSuffix(got[0].String(),
+			": func lib.Unused is unused outside its package"))
+	})
+	t.Run("WithTests", func(t *testing.T) {
+		got, err := UnusedExports(pkgs, true)
+		tmust(t, err)
+		teq(t, []string{"Unused", "UnusedType", "UnusedConst", "Use"}, names(got))
+	})
+	t.Run("ImportAllFailure", func(t *testing.T) {
+		pkgs, err := ctx.ImportAll(unused+"lib", "thislibrarydoesntexist")
+		if err == nil {
+			t.Errorf("expected error for non-existent import")
+		}
+		if pkgs != nil {
+			t.Errorf("expected nil pkgs for non-existent import")
+		}
+	})
+}